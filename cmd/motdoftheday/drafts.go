@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// listDrafts prints the id, title and scheduled publish time of every draft.
+func listDrafts(v *validator.Validate, cfg *Config, args []string) error {
+	parseArgs(newFlagSet("list-drafts", "list-drafts"), args, 0)
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	drafts, apiErr := processor.Drafts(apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL TITLE\tTITLE\tPUBLISH AT")
+	for i := range drafts {
+		publish_at := ""
+		if drafts[i].PublishAt != nil {
+			publish_at = time.Unix(*drafts[i].PublishAt, 0).UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", drafts[i].ID, drafts[i].UrlTitle, drafts[i].Title, publish_at)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// export writes a zip archive of the whole database to a file.
+func export(v *validator.Validate, cfg *Config, args []string) error {
+	args = parseArgs(newFlagSet("export", "export <archive.zip>"), args, 1)
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	file := args[0]
+	f, err := os.Create(file)
+	if err != nil {
+		msg := "Error creating archive '" + file + "': " + err.Error()
+		return errors.New(msg)
+	}
+	apiErr := processor.Export(f, apierror.MethodHTTP)
+	if err := f.Close(); err != nil && apiErr == nil {
+		msg := "Error writing archive '" + file + "': " + err.Error()
+		return errors.New(msg)
+	}
+	if apiErr != nil {
+		os.Remove(file)
+		return apiErr
+	}
+	log.Println("Exported database to " + file)
+	return nil
+}
+
+// importArchive restores an archive written by export into an empty database.
+// Imported users have no password until one is set for them.
+func importArchive(v *validator.Validate, cfg *Config, args []string) error {
+	args = parseArgs(newFlagSet("import-archive", "import-archive <archive.zip>"), args, 1)
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	file := args[0]
+	f, err := os.Open(file)
+	if err != nil {
+		msg := "Error opening archive '" + file + "': " + err.Error()
+		return errors.New(msg)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		msg := "Error reading archive '" + file + "': " + err.Error()
+		return errors.New(msg)
+	}
+	apiErr := processor.ImportArchive(f, info.Size(), apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	log.Println("Imported archive " + file)
+	log.Println("Archives hold no passwords, imported users need a new password before they can log in")
+	return nil
+}
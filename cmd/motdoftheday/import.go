@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/importer"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// importJekyll imports the posts of an existing Jekyll site.
+func importJekyll(v *validator.Validate, cfg *Config, args []string) error {
+	fs := newFlagSet("import", "import [flags] <_posts dir>")
+	author := fs.String("author", "admin", "user to import posts as when their author is missing or unknown")
+	category := fs.String("category", "uncategorized", "category for posts that have none")
+	tag := fs.String("tag", "imported", "tag for posts that have none")
+	args = parseArgs(fs, args, 1)
+	db, sqlxDB, err := database.New(cfg.MotdOfTheDay.Database)
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+	imp := importer.New(importer.Config{
+		Author:   *author,
+		Category: *category,
+		Tag:      *tag,
+	}, db)
+	result, err := imp.Jekyll(args[0])
+	if err != nil {
+		return err
+	}
+	for i := range result.Imported {
+		log.Println("Imported " + result.Imported[i])
+	}
+	for i := range result.Skipped {
+		log.Println("Skipped " + result.Skipped[i].File + ": " + result.Skipped[i].Reason)
+	}
+	log.Printf("Imported %d posts, skipped %d files\n", len(result.Imported), len(result.Skipped))
+	return nil
+}
@@ -1,82 +1,122 @@
 package main
 
 import (
-	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"sort"
 
 	_ "github.com/mattn/go-sqlite3"
-	"gitlab.com/joshraphael/motdoftheday/internal/server/rest"
 	"gitlab.com/joshraphael/motdoftheday/pkg/config"
 	"gitlab.com/joshraphael/motdoftheday/pkg/database"
 	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
 	"gopkg.in/go-playground/validator.v9"
 	yaml "gopkg.in/yaml.v2"
-
-	"github.com/gorilla/mux"
 )
 
 type Config struct {
 	MotdOfTheDay config.Config `yaml:"motdoftheday" validate:"required"`
 }
 
+type command struct {
+	usage string
+	run   func(v *validator.Validate, cfg *Config, args []string) error
+}
+
+var commands = map[string]command{
+	"serve":          {usage: "run the web editor and the publish scheduler", run: serve},
+	"migrate":        {usage: "migrate the database schema", run: migrate},
+	"list-drafts":    {usage: "list posts that have not been published", run: listDrafts},
+	"list-published": {usage: "list the files published to the site", run: listPublished},
+	"publish":        {usage: "publish a post", run: publish},
+	"render":         {usage: "print a post as it would be published", run: render},
+	"user":           {usage: "manage users", run: userCommand},
+	"import":         {usage: "import the posts of a Jekyll site", run: importJekyll},
+	"export":         {usage: "write a zip archive of the database", run: export},
+	"import-archive": {usage: "restore an archive into an empty database", run: importArchive},
+}
+
+// Runs a subcommand, serve when none is given:
+//
+//	motdoftheday [-config file] [command] [arguments]
 func main() {
-	r := mux.NewRouter().StrictSlash(true)
+	conf := flag.String("config", os.Getenv("CONFIG_ENV"), "config file, defaults to $CONFIG_ENV")
+	flag.Usage = usage
+	flag.Parse()
+	name := "serve"
+	args := []string{}
+	if flag.NArg() > 0 {
+		name = flag.Arg(0)
+		args = flag.Args()[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintln(flag.CommandLine.Output(), "unknown command '"+name+"'")
+		usage()
+		os.Exit(2)
+	}
 	v := validator.New()
-	conf := os.Getenv("CONFIG_ENV")
-	cfg, err := initConfig(v, conf)
-	db, sqlxDB, err := database.New(cfg.MotdOfTheDay.Database)
+	cfg, err := initConfig(v, *conf)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	defer sqlxDB.Close()
-	processor := processors.New(cfg.MotdOfTheDay.Processors, db)
-	apiHandler := rest.New(v, processor)
-	r.HandleFunc("/", apiHandler.HomeHandler).Methods("GET")
-	r.HandleFunc("/drafts", apiHandler.DraftsHandler).Methods("GET")
-	r.HandleFunc("/drafts/{post_id}", apiHandler.DraftHandler).Methods("GET")
-	r.HandleFunc("/edit/{post_history_id}", apiHandler.EditHandler).Methods("GET")
-	// Serve static files
-	s := http.StripPrefix("/static/", http.FileServer(http.Dir("./static/")))
-	r.PathPrefix("/static").Handler(s).Methods("GET")
+	if err := cmd.run(v, cfg, args); err != nil {
+		log.Fatalln(err)
+	}
+}
 
-	// API
-	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/submit", apiHandler.SubmitHandler).Methods("POST")
-	api.HandleFunc("/save", apiHandler.SaveHandler).Methods("POST")
-	http.Handle("/", r)
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: motdoftheday [-config file] [command] [arguments]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "commands:")
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-16s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "flags:")
+	flag.PrintDefaults()
+}
 
-	// Start HTTP Server
-	addr := cfg.MotdOfTheDay.Rest.Host + ":" + cfg.MotdOfTheDay.Rest.Port
-	server := &http.Server{
-		Addr:    addr,
-		Handler: nil,
+// newFlagSet returns the flags of a command, printing usage followed by the
+// flag defaults on -h or bad input.
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: motdoftheday "+usage)
+		fs.PrintDefaults()
 	}
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalln(err)
-		}
-	}()
+	return fs
+}
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Println("Serving at: " + addr)
-	<-stop
+// parseArgs parses the flags of a command, which must be followed by exactly
+// n arguments.
+func parseArgs(fs *flag.FlagSet, args []string, n int) []string {
+	fs.Parse(args)
+	if fs.NArg() != n {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Args()
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer func() {
-		cancel()
-	}()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalln(err)
+// open migrates the database and returns a processor for it along with a
+// function that closes the database.
+func open(cfg *Config) (*processors.Processor, func() error, error) {
+	db, sqlxDB, err := database.New(cfg.MotdOfTheDay.Database)
+	if err != nil {
+		return nil, nil, err
 	}
-	log.Println("Server Shutdown Properly")
+	processor := processors.New(cfg.MotdOfTheDay.Processors, db)
+	return &processor, sqlxDB.Close, nil
 }
 
 func initConfig(v *validator.Validate, file string) (*Config, error) {
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// migrate applies every migration, or moves the schema up or down to the
+// version given with -to.
+func migrate(v *validator.Validate, cfg *Config, args []string) error {
+	fs := newFlagSet("migrate", "migrate [-to version]")
+	to := fs.Int64("to", -1, "schema version to migrate to, 0 rolls back every migration")
+	parseArgs(fs, args, 0)
+	db, sqlxDB, err := database.Open(cfg.MotdOfTheDay.Database)
+	if err != nil {
+		return err
+	}
+	defer sqlxDB.Close()
+	if *to < 0 {
+		err = db.Migrate()
+	} else {
+		err = db.MigrateTo(*to)
+	}
+	if err != nil {
+		return err
+	}
+	version, err := db.GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version %d\n", version)
+	return nil
+}
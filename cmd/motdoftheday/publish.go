@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// publish publishes a draft right away, the same as the Publish now button.
+func publish(v *validator.Validate, cfg *Config, args []string) error {
+	args = parseArgs(newFlagSet("publish", "publish <post_id>"), args, 1)
+	post_id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		msg := "invalid post_id '" + args[0] + "': " + err.Error()
+		return errors.New(msg)
+	}
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	complete_post, apiErr := processor.Publish(post_id, apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	log.Println("Published " + complete_post.Post.UrlTitle + " as " + complete_post.Post.PublishedName)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// listPublished prints the files in the post directory of the site.
+func listPublished(v *validator.Validate, cfg *Config, args []string) error {
+	parseArgs(newFlagSet("list-published", "list-published"), args, 0)
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	names, apiErr := processor.PublishedFiles(apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	for i := range names {
+		fmt.Println(names[i])
+	}
+	return nil
+}
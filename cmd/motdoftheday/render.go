@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// render prints a post as it would be published, without publishing it.
+func render(v *validator.Validate, cfg *Config, args []string) error {
+	args = parseArgs(newFlagSet("render", "render <post_id>"), args, 1)
+	post_id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		msg := "invalid post_id '" + args[0] + "': " + err.Error()
+		return errors.New(msg)
+	}
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	_, data, apiErr := processor.Render(post_id, apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/internal/server/rest"
+	"gitlab.com/joshraphael/motdoftheday/internal/server/rpc"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+	"gitlab.com/joshraphael/motdoftheday/pkg/scheduler"
+	"google.golang.org/grpc"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// serve runs the web editor, the gRPC service when it has a port, and the
+// scheduler until the process is interrupted.
+func serve(v *validator.Validate, cfg *Config, args []string) error {
+	fs := newFlagSet("serve", "serve [-dev]")
+	dev := fs.Bool("dev", false, "read templates and static files from the working directory on every request")
+	parseArgs(fs, args, 0)
+	if *dev {
+		cfg.MotdOfTheDay.Rest.Dev = true
+	}
+	r := mux.NewRouter().StrictSlash(true)
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	password, apiErr := processor.BootstrapPassword("admin", cfg.MotdOfTheDay.Rest.AdminPassword, apierror.MethodHTTP)
+	if apiErr != nil {
+		return apiErr
+	}
+	if password != "" && cfg.MotdOfTheDay.Rest.AdminPassword == "" {
+		// Only shown on the first run, change it once logged in
+		log.Println("Generated password for admin: " + password)
+	}
+	sched, err := scheduler.New(cfg.MotdOfTheDay.Scheduler, *processor)
+	if err != nil {
+		return err
+	}
+	apiHandler, err := rest.New(cfg.MotdOfTheDay.Rest, v, *processor)
+	if err != nil {
+		return err
+	}
+	r.Use(apiHandler.RequestIDMiddleware)
+	// Login and the static assets it needs are the only public routes
+	r.HandleFunc("/login", apiHandler.LoginPageHandler).Methods("GET")
+	r.HandleFunc("/api/login", apiHandler.LoginHandler).Methods("POST")
+	// Serve static files
+	s := http.StripPrefix("/static/", apiHandler.StaticHandler())
+	r.PathPrefix("/static").Handler(s).Methods("GET")
+
+	authed := r.NewRoute().Subrouter()
+	authed.Use(apiHandler.AuthMiddleware)
+	authed.HandleFunc("/", apiHandler.HomeHandler).Methods("GET")
+	authed.HandleFunc("/drafts", apiHandler.DraftsHandler).Methods("GET")
+	authed.HandleFunc("/drafts/{post_id}", apiHandler.DraftHandler).Methods("GET")
+	authed.HandleFunc("/edit/{post_history_id}", apiHandler.EditHandler).Methods("GET")
+
+	// API
+	api := authed.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/logout", apiHandler.LogoutHandler).Methods("POST")
+	api.HandleFunc("/submit", apiHandler.SubmitHandler).Methods("POST")
+	api.HandleFunc("/save", apiHandler.SaveHandler).Methods("POST")
+	api.HandleFunc("/preview", apiHandler.PreviewHandler).Methods("POST")
+	api.HandleFunc("/autosave", apiHandler.AutosaveHandler).Methods("POST")
+	api.HandleFunc("/autosave/{url_title}", apiHandler.WorkingCopyHandler).Methods("GET")
+	api.HandleFunc("/assets", apiHandler.UploadAssetHandler).Methods("POST")
+	api.HandleFunc("/assets/{asset_id}", apiHandler.AssetHandler).Methods("GET")
+	api.HandleFunc("/assets/{asset_id}/{name}", apiHandler.AssetHandler).Methods("GET")
+	api.HandleFunc("/posts", apiHandler.PostsHandler).Methods("GET")
+	api.HandleFunc("/posts", apiHandler.CreatePostHandler).Methods("POST")
+	api.HandleFunc("/posts/{post_id}", apiHandler.PostHandler).Methods("GET")
+	api.HandleFunc("/posts/{post_id}", apiHandler.UpdatePostHandler).Methods("PUT")
+	api.HandleFunc("/posts/{post_id}", apiHandler.DeletePostHandler).Methods("DELETE")
+	api.HandleFunc("/posts/{post_id}/history", apiHandler.HistoryHandler).Methods("GET")
+	api.HandleFunc("/posts/{post_id}/diff", apiHandler.DiffHandler).Methods("GET")
+	api.HandleFunc("/history/{post_history_id}/restore", apiHandler.RestoreHandler).Methods("POST")
+	api.HandleFunc("/posts/{post_id}/schedule", apiHandler.SchedulePostHandler).Methods("PUT")
+	api.HandleFunc("/posts/{post_id}/schedule", apiHandler.UnschedulePostHandler).Methods("DELETE")
+	api.HandleFunc("/posts/{post_id}/publish", apiHandler.PublishPostHandler).Methods("POST")
+	api.HandleFunc("/posts/{post_id}/unpublish", apiHandler.UnpublishPostHandler).Methods("POST")
+	api.HandleFunc("/search", apiHandler.SearchHandler).Methods("GET")
+	api.HandleFunc("/export", apiHandler.ExportHandler).Methods("GET")
+	api.HandleFunc("/tags", apiHandler.TagsHandler).Methods("GET")
+	api.HandleFunc("/categories", apiHandler.CategoriesHandler).Methods("GET")
+	api.HandleFunc("/users", apiHandler.UsersHandler).Methods("GET")
+	api.HandleFunc("/users", apiHandler.CreateUserHandler).Methods("POST")
+	api.HandleFunc("/users/{user_id}", apiHandler.UserHandler).Methods("GET")
+	api.HandleFunc("/users/{user_id}", apiHandler.UpdateUserHandler).Methods("PUT")
+	api.HandleFunc("/users/{user_id}", apiHandler.DeleteUserHandler).Methods("DELETE")
+	api.HandleFunc("/users/{user_id}/password", apiHandler.PasswordHandler).Methods("PUT")
+	api.HandleFunc("/tokens", apiHandler.ApiTokensHandler).Methods("GET")
+	api.HandleFunc("/tokens", apiHandler.CreateApiTokenHandler).Methods("POST")
+	api.HandleFunc("/tokens/{token_id}", apiHandler.DeleteApiTokenHandler).Methods("DELETE")
+	http.Handle("/", r)
+
+	// Start HTTP Server
+	addr := cfg.MotdOfTheDay.Rest.Host + ":" + cfg.MotdOfTheDay.Rest.Port
+	server := &http.Server{
+		Addr:    addr,
+		Handler: nil,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalln(err)
+		}
+	}()
+	// Start gRPC Server
+	var grpcServer *grpc.Server
+	if cfg.MotdOfTheDay.Grpc.Port != "" {
+		grpcAddr := cfg.MotdOfTheDay.Grpc.Host + ":" + cfg.MotdOfTheDay.Grpc.Port
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		rpcHandler := rpc.New(cfg.MotdOfTheDay.Grpc, *processor)
+		grpcServer = grpc.NewServer(grpc.UnaryInterceptor(rpcHandler.AuthInterceptor))
+		motdpb.RegisterMotdServiceServer(grpcServer, rpcHandler)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalln(err)
+			}
+		}()
+		log.Println("Serving gRPC at: " + grpcAddr)
+	}
+	// Publish scheduled drafts in the background
+	sched.Start()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	log.Println("Serving at: " + addr)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer func() {
+		cancel()
+	}()
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+	if grpcServer != nil {
+		if err := stopGrpc(ctx, grpcServer); err != nil {
+			return err
+		}
+	}
+	if err := sched.Shutdown(ctx); err != nil {
+		return err
+	}
+	log.Println("Server Shutdown Properly")
+	return nil
+}
+
+// stopGrpc waits for calls in progress to finish like http.Server.Shutdown,
+// cutting them off when ctx is done.
+func stopGrpc(ctx context.Context, s *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// userCommand runs the user subcommands:
+//
+//	user add [-password-stdin] <user_name> <first_name> <last_name>
+func userCommand(v *validator.Validate, cfg *Config, args []string) error {
+	if len(args) == 0 || args[0] != "add" {
+		fmt.Fprintln(os.Stderr, "usage: motdoftheday user add [-password-stdin] <user_name> <first_name> <last_name>")
+		os.Exit(2)
+	}
+	fs := newFlagSet("user add", "user add [-password-stdin] <user_name> <first_name> <last_name>")
+	password_stdin := fs.Bool("password-stdin", false, "read the password of the user from the first line of stdin")
+	args = parseArgs(fs, args[1:], 3)
+	password := ""
+	if *password_stdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			msg := "cannot read password from stdin: " + err.Error()
+			return errors.New(msg)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	processor, closer, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	u := user.New(apierror.MethodHTTP)
+	u.Username = args[0]
+	u.Firstname = args[1]
+	u.Lastname = args[2]
+	db_user, apiErr := processor.CreateUser(u)
+	if apiErr != nil {
+		return apiErr
+	}
+	if *password_stdin {
+		apiErr = processor.SetPassword(db_user.ID, password, apierror.MethodHTTP)
+		if apiErr != nil {
+			// Do not leave behind a user that cannot log in
+			if delErr := processor.DeleteUser(db_user.ID, apierror.MethodHTTP); delErr != nil {
+				msg := apiErr.Error() + ": cannot remove user " + db_user.Username + ": " + delErr.Error()
+				return errors.New(msg)
+			}
+			return apiErr
+		}
+	}
+	log.Printf("Added user %s with id %d\n", db_user.Username, db_user.ID)
+	return nil
+}
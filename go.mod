@@ -1,13 +1,25 @@
 module gitlab.com/joshraphael/motdoftheday
 
+go 1.21
+
 require (
-	github.com/go-playground/locales v0.12.1 // indirect
-	github.com/go-playground/universal-translator v0.16.0 // indirect
 	github.com/gorilla/mux v1.7.0
 	github.com/jmoiron/sqlx v1.2.0
-	github.com/leodido/go-urn v1.1.0 // indirect
 	github.com/mattn/go-sqlite3 v1.10.0
-	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
+	golang.org/x/crypto v0.21.0
+	golang.org/x/net v0.22.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.2
 	gopkg.in/go-playground/validator.v9 v9.30.0
 	gopkg.in/yaml.v2 v2.2.4
 )
+
+require (
+	github.com/go-playground/locales v0.12.1 // indirect
+	github.com/go-playground/universal-translator v0.16.0 // indirect
+	github.com/leodido/go-urn v1.1.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
+)
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+type assetResponse struct {
+	*database.Asset
+	URL string `json:"url"`
+}
+
+// UploadAssetHandler stores the file sent as the asset field of a multipart
+// form and returns the url the editor can show it at.
+func (r Rest) UploadAssetHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		method := apierror.MethodHTTP
+		// Leave room for the rest of the form so that an asset that is too
+		// large is reported by the processor
+		req.Body = http.MaxBytesReader(w, req.Body, processors.MAX_ASSET_SIZE+(1<<20))
+		f, header, err := req.FormFile("asset")
+		if err != nil {
+			msg := "Error reading asset upload: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		defer f.Close()
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			msg := "Error reading asset upload data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		asset, apiErr := r.processor.UploadAsset(author(req), header.Filename, data, method)
+		if apiErr != nil {
+			msg := "Error processing asset upload: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Uploaded asset " + asset.Name)
+		writeJSON(w, http.StatusCreated, assetResponse{
+			Asset: asset,
+			URL:   processors.EditorAssetURL(asset),
+		})
+	}
+}
+
+// AssetHandler serves an uploaded asset to the editor. Assets never change, so
+// they can be cached, and they are sandboxed so that an uploaded page or SVG
+// cannot run scripts as the editor.
+func (r Rest) AssetHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		asset_id, err := strconv.Atoi(vars["asset_id"])
+		if err != nil {
+			msg := "invalid asset_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		asset, apiErr := r.processor.GetAsset(int64(asset_id), method)
+		if apiErr != nil {
+			msg := "Error getting asset: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		w.Header().Set("Content-Type", asset.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(asset.Size, 10))
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		w.Write(asset.Data)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+// uploadRequest is a multipart upload of data as the form field field.
+func uploadRequest(t *testing.T, u *database.User, field string, name string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(field, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := testRequest("POST", "/api/assets", body.String(), u, nil)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestUploadAssetHandler(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+	tests := []struct {
+		name  string
+		field string
+		data  []byte
+	}{
+		{"wrong field", "file", []byte("data")},
+		{"empty", "asset", []byte{}},
+		{"too large", "asset", make([]byte, processors.MAX_ASSET_SIZE+1)},
+		{"larger than the body limit", "asset", make([]byte, processors.MAX_ASSET_SIZE+(2<<20))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.UploadAssetHandler(w, uploadRequest(t, admin, tt.field, "cat.png", tt.data))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("got status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+		})
+	}
+	stored, err := db.GetAssetById(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != nil {
+		t.Errorf("a rejected upload was stored as %+v", stored)
+	}
+
+	w := httptest.NewRecorder()
+	r.UploadAssetHandler(w, uploadRequest(t, nil, "asset", "cat.png", []byte("data")))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d uploading without a user, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w = httptest.NewRecorder()
+	r.UploadAssetHandler(w, uploadRequest(t, admin, "asset", "../My Cat.png", []byte("data")))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var resp struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Name != "my-cat.png" || resp.URL != "/api/assets/"+strconv.FormatInt(resp.ID, 10)+"/my-cat.png" {
+		t.Errorf("got response %s", w.Body.String())
+	}
+}
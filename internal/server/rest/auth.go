@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+const sessionCookie string = "motdoftheday_session"
+
+type contextKey string
+
+const authorKey contextKey = "author"
+
+// AuthMiddleware requires a session cookie or an "Authorization: Bearer"
+// API token. Unauthenticated page requests are sent to the login page.
+func (r Rest) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, apiErr := r.processor.Authenticate(requestToken(req), apierror.MethodHTTP)
+		if apiErr != nil {
+			if !strings.HasPrefix(req.URL.Path, "/api/") && apiErr.Status() == apierror.StatusUnauthorized {
+				http.Redirect(w, req, "/login", http.StatusSeeOther)
+				return
+			}
+			msg := "Error authenticating request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		ctx := context.WithValue(req.Context(), authorKey, user)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func requestToken(req *http.Request) string {
+	header := req.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	if cookie, err := req.Cookie(sessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func author(req *http.Request) *database.User {
+	user, _ := req.Context().Value(authorKey).(*database.User)
+	return user
+}
+
+// selfOrAdmin reports whether the user making req may change the user
+// user_id. Only admins may change other users.
+func selfOrAdmin(req *http.Request, user_id int64) bool {
+	user := author(req)
+	return user != nil && (user.ID == user_id || user.IsAdmin())
+}
+
+func isAdmin(req *http.Request) bool {
+	user := author(req)
+	return user != nil && user.IsAdmin()
+}
+
+func authorName(req *http.Request) string {
+	if user := author(req); user != nil {
+		return user.Username
+	}
+	return ""
+}
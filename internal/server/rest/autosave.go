@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// AutosaveHandler stores the post being edited as its working copy, which
+// the editor sends periodically.
+func (r Rest) AutosaveHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading autosave request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		post := post.New(apierror.MethodHTTP, authorName(req))
+		if err := json.Unmarshal(data, &post); err != nil {
+			msg := "Error marshalling autosave json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		working_copy, revision, apiErr := r.processor.Autosave(post)
+		if apiErr != nil {
+			msg := "Error processing autosave request: " + apiErr.Error()
+			log.Println(msg)
+			if apiErr.Status() == apierror.StatusConflict {
+				writeConflict(w, req, msg, apiErr, revision)
+				return
+			}
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, working_copy)
+	}
+}
+
+// WorkingCopyHandler returns the autosaved working copy of a post, so that
+// the editor can offer to restore changes that were never saved.
+func (r Rest) WorkingCopyHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		working_copy, apiErr := r.processor.GetWorkingCopy(vars["url_title"], apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error getting working copy: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, working_copy)
+	}
+}
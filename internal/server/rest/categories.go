@@ -0,0 +1,21 @@
+package rest
+
+import (
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func (r Rest) CategoriesHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		categories, apiErr := r.processor.Categories(apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error gathering categories: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, categories)
+	}
+}
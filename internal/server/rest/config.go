@@ -1,6 +1,12 @@
 package rest
 
 type Config struct {
-	Host string `yaml:"host" validate:"required"`
-	Port string `yaml:"port" validate:"required"`
+	Host          string `yaml:"host" validate:"required"`
+	Port          string `yaml:"port" validate:"required"`
+	SessionTTL    string `yaml:"session_ttl"`
+	SecureCookies bool   `yaml:"secure_cookies"`
+	AdminPassword string `yaml:"admin_password"`
+	// Dev reads templates and static files from the working directory on
+	// every request instead of the copies built into the binary.
+	Dev bool `yaml:"dev"`
 }
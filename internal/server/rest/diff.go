@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+// DiffHandler compares two revisions of a post given by the from and to
+// post_history ids in the query. Either may be left out to compare the latest
+// revision with the one before it.
+func (r Rest) DiffHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		params := req.URL.Query()
+		revisions := map[string]int64{}
+		for _, name := range []string{"from", "to"} {
+			value := params.Get(name)
+			if value == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				msg := "invalid " + name + " in query: " + err.Error()
+				log.Println(msg)
+				apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+				writeError(w, req, msg, apiErr)
+				return
+			}
+			revisions[name] = id
+		}
+		diff, apiErr := r.processor.Diff(int64(post_id), revisions["from"], revisions["to"], method)
+		if apiErr != nil {
+			msg := "Error processing diff request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, diff)
+	}
+}
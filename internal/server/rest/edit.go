@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"text/template"
 
 	"github.com/gorilla/mux"
 	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
@@ -13,7 +12,6 @@ import (
 
 func (r Rest) EditHandler(w http.ResponseWriter, req *http.Request) {
 	if req.Method == "GET" {
-		tmpl := template.Must(template.ParseFiles("./templates/edit.html"))
 		vars := mux.Vars(req)
 		method := apierror.MethodHTTP
 		id := vars["post_history_id"]
@@ -21,17 +19,17 @@ func (r Rest) EditHandler(w http.ResponseWriter, req *http.Request) {
 		if err != nil {
 			msg := "invalid post_id in url: " + err.Error()
 			log.Println(msg)
-			apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", method)
-			http.Error(w, msg, apiErr.Code())
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
 			return
 		}
 		post_history, apiErr := r.processor.Edit(int64(post_id), apierror.MethodHTTP)
 		if apiErr != nil {
 			msg := "Error gathering draft posts: " + apiErr.Error()
 			log.Println(msg)
-			http.Error(w, msg, apiErr.Code())
+			writeError(w, req, msg, apiErr)
 			return
 		}
-		tmpl.Execute(w, post_history)
+		r.render(w, req, "edit.html", post_history)
 	}
 }
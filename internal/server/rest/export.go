@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+// exportWriter sends the download headers along with the first bytes of the
+// archive, so that an export which fails before writing anything can still be
+// reported with an error status.
+type exportWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (ew *exportWriter) Write(p []byte) (int, error) {
+	if !ew.started {
+		name := "motdoftheday-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+		ew.w.Header().Set("Content-Type", "application/zip")
+		ew.w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
+		ew.w.WriteHeader(http.StatusOK)
+		ew.started = true
+	}
+	return ew.w.Write(p)
+}
+
+// ExportHandler streams a zip archive of the whole database to an admin
+func (r Rest) ExportHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		method := apierror.MethodHTTP
+		if !isAdmin(req) {
+			msg := "only admins can export the database"
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusForbidden, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		ew := &exportWriter{w: w}
+		apiErr := r.processor.Export(ew, method)
+		if apiErr != nil {
+			msg := "Error exporting database: " + apiErr.Error()
+			log.Println(msg)
+			if ew.started {
+				// The status has already been sent, the client only sees a
+				// truncated archive
+				return
+			}
+			writeError(w, req, msg, apiErr)
+			return
+		}
+	}
+}
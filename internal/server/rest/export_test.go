@@ -0,0 +1,40 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExportHandlerRequiresAdmin(t *testing.T) {
+	r, db := newTestRest(t)
+	alice := testUser(t, db, "alice")
+	w := httptest.NewRecorder()
+	r.ExportHandler(w, testRequest("GET", "/api/export", "", alice, nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w.Header().Get("Content-Disposition") != "" {
+		t.Error("a forbidden export was sent as a download")
+	}
+}
+
+func TestExportHandlerStreamsArchive(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+	w := httptest.NewRecorder()
+	r.ExportHandler(w, testRequest("GET", "/api/export", "", admin, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("got content type %q, want application/zip", ct)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len())); err != nil {
+		t.Errorf("export is not a zip archive: %v", err)
+	}
+}
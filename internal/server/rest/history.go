@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func (r Rest) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		history, apiErr := r.processor.History(int64(post_id), method)
+		if apiErr != nil {
+			msg := "Error gathering post history: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, history)
+	}
+}
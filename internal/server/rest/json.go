@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		msg := "Error marshalling json response: " + err.Error()
+		log.Println(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// writeError sends apiErr to the client as a JSON envelope, described by msg.
+// The request id is logged so that the error a client reports can be found.
+func writeError(w http.ResponseWriter, req *http.Request, msg string, apiErr apierror.IApiError) {
+	log.Println("Request " + requestID(req) + " failed: " + string(apiErr.Status()))
+	writeJSON(w, apiErr.Code(), apierror.NewEnvelope(apiErr, msg, requestID(req)))
+}
+
+type conflictResponse struct {
+	apierror.Envelope
+	Revision interface{} `json:"revision"`
+}
+
+// writeConflict tells the client that what it edited has changed since, and
+// sends the current revision so that it can be merged.
+func writeConflict(w http.ResponseWriter, req *http.Request, msg string, apiErr apierror.IApiError, revision interface{}) {
+	log.Println("Request " + requestID(req) + " failed: " + string(apiErr.Status()))
+	writeJSON(w, apiErr.Code(), conflictResponse{
+		Envelope: apierror.NewEnvelope(apiErr, msg, requestID(req)),
+		Revision: revision,
+	})
+}
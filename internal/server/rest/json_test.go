@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+type titleError struct{}
+
+func (titleError) Error() string {
+	return "invalid title"
+}
+
+func (titleError) FieldErrors() []apierror.FieldError {
+	return []apierror.FieldError{{Field: "title", Message: "must be letters"}}
+}
+
+// serveError sends apiErr from a handler behind RequestIDMiddleware, for a
+// request with the X-Request-Id request_id.
+func serveError(request_id string, send func(w http.ResponseWriter, req *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/posts", nil)
+	if request_id != "" {
+		req.Header.Set(requestIDHeader, request_id)
+	}
+	w := httptest.NewRecorder()
+	Rest{}.RequestIDMiddleware(http.HandlerFunc(send)).ServeHTTP(w, req)
+	return w
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status apierror.Status
+		code   int
+		fields []apierror.FieldError
+	}{
+		{"not found", errors.New("no post"), apierror.StatusNotFound, http.StatusNotFound, []apierror.FieldError{}},
+		{"fields", titleError{}, apierror.StatusBadRequest, http.StatusBadRequest, titleError{}.FieldErrors()},
+		{"internal", errors.New("broken"), apierror.StatusInternal, http.StatusInternalServerError, []apierror.FieldError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveError("req-1", func(w http.ResponseWriter, req *http.Request) {
+				writeError(w, req, "Error doing it: "+tt.err.Error(), apierror.New(tt.err, tt.status, apierror.MethodHTTP))
+			})
+			if w.Code != tt.code {
+				t.Errorf("got status %d, want %d", w.Code, tt.code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got content type %q", ct)
+			}
+			var envelope map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []string{"status", "code", "message", "fields", "request_id"} {
+				if _, ok := envelope[key]; !ok {
+					t.Errorf("envelope %s has no %s", w.Body.String(), key)
+				}
+			}
+			var got apierror.Envelope
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			want := apierror.Envelope{
+				Status:    string(tt.status),
+				Code:      tt.code,
+				Message:   "Error doing it: " + tt.err.Error(),
+				Fields:    tt.fields,
+				RequestID: "req-1",
+			}
+			if got.Status != want.Status || got.Code != want.Code || got.Message != want.Message || got.RequestID != want.RequestID || len(got.Fields) != len(want.Fields) {
+				t.Errorf("got envelope %+v, want %+v", got, want)
+			}
+			for i := range want.Fields {
+				if got.Fields[i] != want.Fields[i] {
+					t.Errorf("got field %+v, want %+v", got.Fields[i], want.Fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteConflict(t *testing.T) {
+	revision := map[string]interface{}{"id": float64(2), "body": "<p>latest</p>"}
+	w := serveError("req-2", func(w http.ResponseWriter, req *http.Request) {
+		apiErr := apierror.New(errors.New("changed"), apierror.StatusConflict, apierror.MethodHTTP)
+		writeConflict(w, req, "Error saving: changed", apiErr, revision)
+	})
+	if w.Code != http.StatusConflict {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusConflict)
+	}
+	var got struct {
+		apierror.Envelope
+		Revision map[string]interface{} `json:"revision"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "CONFLICT" || got.Code != http.StatusConflict || got.Message != "Error saving: changed" || got.RequestID != "req-2" || got.Fields == nil {
+		t.Errorf("got envelope %s", w.Body.String())
+	}
+	if got.Revision["id"] != revision["id"] || got.Revision["body"] != revision["body"] {
+		t.Errorf("got revision %v, want %v", got.Revision, revision)
+	}
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+type loginRequest struct {
+	Username string `json:"user_name"`
+	Password string `json:"password"`
+}
+
+func (r Rest) LoginPageHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		r.render(w, req, "login.html", nil)
+	}
+}
+
+func (r Rest) LoginHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading login request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		var login loginRequest
+		if err := json.Unmarshal(data, &login); err != nil {
+			msg := "Error marshalling login json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		token, user, apiErr := r.processor.Login(login.Username, login.Password, r.sessionTTL, apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error processing login request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  time.Now().Add(r.sessionTTL),
+			HttpOnly: true,
+			Secure:   r.cfg.SecureCookies,
+			SameSite: http.SameSiteStrictMode,
+		})
+		log.Println("Logged in " + user.Username)
+		writeJSON(w, http.StatusOK, user)
+	}
+}
+
+func (r Rest) LogoutHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		if cookie, err := req.Cookie(sessionCookie); err == nil {
+			if apiErr := r.processor.Logout(cookie.Value, apierror.MethodHTTP); apiErr != nil {
+				msg := "Error processing logout request: " + apiErr.Error()
+				log.Println(msg)
+				writeError(w, req, msg, apiErr)
+				return
+			}
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   r.cfg.SecureCookies,
+			SameSite: http.SameSiteStrictMode,
+		})
+		w.WriteHeader(http.StatusNoContent)
+		log.Println("Logged out")
+	}
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+type passwordRequest struct {
+	Password string `json:"password"`
+}
+
+// PasswordHandler lets a user change their own password, or an admin change
+// the password of any user.
+func (r Rest) PasswordHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "PUT" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		user_id, err := strconv.Atoi(vars["user_id"])
+		if err != nil {
+			msg := "invalid user_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if !selfOrAdmin(req, int64(user_id)) {
+			msg := "cannot change the password of another user"
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusForbidden, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading password request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		var password passwordRequest
+		if err := json.Unmarshal(data, &password); err != nil {
+			msg := "Error marshalling password json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if apiErr := r.processor.SetPassword(int64(user_id), password.Password, method); apiErr != nil {
+			msg := "Error processing password request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		log.Println("Changed password")
+	}
+}
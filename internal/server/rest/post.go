@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+func (r Rest) PostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.GetPost(int64(post_id), method)
+		if apiErr != nil {
+			msg := "Error gathering post: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
+
+func (r Rest) UpdatePostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "PUT" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading update post request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		p := post.New(method, authorName(req))
+		if err := json.Unmarshal(data, &p); err != nil {
+			msg := "Error marshalling update post json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.UpdatePost(int64(post_id), p)
+		if apiErr != nil {
+			msg := "Error processing update post request: " + apiErr.Error()
+			log.Println(msg)
+			if apiErr.Status() == apierror.StatusConflict {
+				writeConflict(w, req, msg, apiErr, complete_post)
+				return
+			}
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Updated post")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
+
+func (r Rest) DeletePostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "DELETE" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if apiErr := r.processor.DeletePost(int64(post_id), method); apiErr != nil {
+			msg := "Error processing delete post request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		log.Println("Deleted post")
+	}
+}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+func (r Rest) PostsHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		posts, apiErr := r.processor.Posts(apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error gathering posts: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, posts)
+	}
+}
+
+func (r Rest) CreatePostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading create post request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		p := post.New(apierror.MethodHTTP, authorName(req))
+		if err := json.Unmarshal(data, &p); err != nil {
+			msg := "Error marshalling create post json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.CreatePost(p)
+		if apiErr != nil {
+			msg := "Error processing create post request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Created post")
+		writeJSON(w, http.StatusCreated, complete_post)
+	}
+}
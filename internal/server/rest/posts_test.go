@@ -0,0 +1,173 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// decode checks that w has the status code and decodes its body into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, code int, v interface{}) {
+	t.Helper()
+	if w.Code != code {
+		t.Fatalf("got status %d, want %d: %s", w.Code, code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q, want application/json", ct)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("cannot decode %s: %v", w.Body.String(), err)
+	}
+}
+
+// decodeError checks that w is an error envelope with status.
+func decodeError(t *testing.T, w *httptest.ResponseRecorder, status apierror.Status) apierror.Envelope {
+	t.Helper()
+	code := apierror.New(errors.New(string(status)), status, apierror.MethodHTTP).Code()
+	var envelope apierror.Envelope
+	decode(t, w, code, &envelope)
+	if envelope.Status != string(status) || envelope.Code != code || envelope.Message == "" {
+		t.Errorf("got error %+v, want %s", envelope, status)
+	}
+	return envelope
+}
+
+func postJSON(title string, body string) string {
+	return `{"title":"` + title + `","tags":["Go","test"],"categories":["testing"],"body":"` + body + `"}`
+}
+
+func TestPostHandlers(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+
+	w := httptest.NewRecorder()
+	r.PostsHandler(w, testRequest("GET", "/api/posts", "", admin, nil))
+	var posts []database.Post
+	decode(t, w, http.StatusOK, &posts)
+	if posts == nil || len(posts) != 0 {
+		t.Errorf("got posts %v, want an empty list", posts)
+	}
+
+	w = httptest.NewRecorder()
+	r.CreatePostHandler(w, testRequest("POST", "/api/posts", postJSON("Handled Post", "<p>first</p>"), admin, nil))
+	var created database.CompletePost
+	decode(t, w, http.StatusCreated, &created)
+	if created.Post == nil || created.Post.Title != "Handled Post" || created.Post.UserID != admin.ID {
+		t.Fatalf("created %+v", created.Post)
+	}
+	if len(created.History) != 1 || created.History[0].Body != "<p>first</p>" {
+		t.Errorf("created history %+v", created.History)
+	}
+	if len(created.Tags[created.History[0].ID]) != 2 || len(created.Categories[created.History[0].ID]) != 1 {
+		t.Errorf("created tags %+v and categories %+v", created.Tags, created.Categories)
+	}
+	id := strconv.FormatInt(created.Post.ID, 10)
+	vars := map[string]string{"post_id": id}
+
+	w = httptest.NewRecorder()
+	r.PostHandler(w, testRequest("GET", "/api/posts/"+id, "", admin, vars))
+	var got database.CompletePost
+	decode(t, w, http.StatusOK, &got)
+	if got.Post == nil || got.Post.ID != created.Post.ID || len(got.History) != 1 {
+		t.Errorf("got %+v, want the created post", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.UpdatePostHandler(w, testRequest("PUT", "/api/posts/"+id, postJSON("Handled Post", "<p>second</p>"), admin, vars))
+	var updated database.CompletePost
+	decode(t, w, http.StatusOK, &updated)
+	if len(updated.History) != 2 || updated.History[1].Body != "<p>second</p>" {
+		t.Errorf("updated history %+v", updated.History)
+	}
+
+	w = httptest.NewRecorder()
+	r.HistoryHandler(w, testRequest("GET", "/api/posts/"+id+"/history", "", admin, vars))
+	var history []database.PostHistory
+	decode(t, w, http.StatusOK, &history)
+	if len(history) != 2 || history[0].Body != "<p>first</p>" || history[1].Body != "<p>second</p>" {
+		t.Errorf("got history %+v", history)
+	}
+
+	w = httptest.NewRecorder()
+	r.PostsHandler(w, testRequest("GET", "/api/posts", "", admin, nil))
+	decode(t, w, http.StatusOK, &posts)
+	if len(posts) != 1 || posts[0].ID != created.Post.ID {
+		t.Errorf("got posts %+v, want the created post", posts)
+	}
+
+	w = httptest.NewRecorder()
+	r.TagsHandler(w, testRequest("GET", "/api/tags", "", admin, nil))
+	var tags []database.Tag
+	decode(t, w, http.StatusOK, &tags)
+	if len(tags) != 2 || tags[0].Name == "" || tags[1].Name == "" {
+		t.Errorf("got tags %+v", tags)
+	}
+
+	w = httptest.NewRecorder()
+	r.CategoriesHandler(w, testRequest("GET", "/api/categories", "", admin, nil))
+	var categories []database.Category
+	decode(t, w, http.StatusOK, &categories)
+	if len(categories) != 1 || categories[0].Name != "testing" {
+		t.Errorf("got categories %+v", categories)
+	}
+
+	w = httptest.NewRecorder()
+	r.DeletePostHandler(w, testRequest("DELETE", "/api/posts/"+id, "", admin, vars))
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("got status %d with %q, want %d and no body", w.Code, w.Body.String(), http.StatusNoContent)
+	}
+	w = httptest.NewRecorder()
+	r.PostHandler(w, testRequest("GET", "/api/posts/"+id, "", admin, vars))
+	decodeError(t, w, apierror.StatusNotFound)
+}
+
+func TestPostHandlerErrors(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+	missing := map[string]string{"post_id": "12345"}
+	invalid := map[string]string{"post_id": "abc"}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+		vars    map[string]string
+		status  apierror.Status
+	}{
+		{"create bad json", r.CreatePostHandler, "POST", `{"title":`, nil, apierror.StatusBadRequest},
+		{"create invalid title", r.CreatePostHandler, "POST", postJSON("Bad/Title", "<p>body</p>"), nil, apierror.StatusBadRequest},
+		{"create without body", r.CreatePostHandler, "POST", postJSON("No Body", ""), nil, apierror.StatusBadRequest},
+		{"get invalid id", r.PostHandler, "GET", "", invalid, apierror.StatusBadRequest},
+		{"get missing", r.PostHandler, "GET", "", missing, apierror.StatusNotFound},
+		{"update invalid id", r.UpdatePostHandler, "PUT", postJSON("Missing", "<p>body</p>"), invalid, apierror.StatusBadRequest},
+		{"update bad json", r.UpdatePostHandler, "PUT", `[]`, missing, apierror.StatusBadRequest},
+		{"update missing", r.UpdatePostHandler, "PUT", postJSON("Missing", "<p>body</p>"), missing, apierror.StatusNotFound},
+		{"delete invalid id", r.DeletePostHandler, "DELETE", "", invalid, apierror.StatusBadRequest},
+		{"delete missing", r.DeletePostHandler, "DELETE", "", missing, apierror.StatusNotFound},
+		{"history invalid id", r.HistoryHandler, "GET", "", invalid, apierror.StatusBadRequest},
+		{"history missing", r.HistoryHandler, "GET", "", missing, apierror.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, testRequest(tt.method, "/api/posts", tt.body, admin, tt.vars))
+			decodeError(t, w, tt.status)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.CreatePostHandler(w, testRequest("POST", "/api/posts", postJSON("Taken", "<p>body</p>"), admin, nil))
+	var created database.CompletePost
+	decode(t, w, http.StatusCreated, &created)
+	w = httptest.NewRecorder()
+	r.CreatePostHandler(w, testRequest("POST", "/api/posts", postJSON("taken", "<p>again</p>"), admin, nil))
+	decodeError(t, w, apierror.StatusBadRequest)
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// PreviewHandler renders an unsaved post the way submitting it would publish
+// it, front matter included.
+func (r Rest) PreviewHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading preview request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		post := post.New(apierror.MethodHTTP, authorName(req))
+		if err := json.Unmarshal(data, &post); err != nil {
+			msg := "Error marshalling preview json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		rendered, apiErr := r.processor.Preview(post)
+		if apiErr != nil {
+			msg := "Error processing preview request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, rendered)
+	}
+}
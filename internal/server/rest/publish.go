@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func (r Rest) PublishPostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.Publish(int64(post_id), method)
+		if apiErr != nil {
+			msg := "Error processing publish request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Published post")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
+
+func (r Rest) UnpublishPostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.Unpublish(int64(post_id), method)
+		if apiErr != nil {
+			msg := "Error processing unpublish request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Unpublished post")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
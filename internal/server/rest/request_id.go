@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+const requestIDKey contextKey = "request_id"
+
+// requestIDHeader carries the id of a request, which is sent back with errors
+// so that they can be found in the logs.
+const requestIDHeader string = "X-Request-Id"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware gives every request an id, keeping the one set by a
+// proxy in front of the server when there is one.
+func (r Rest) RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		request_id := req.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(request_id) {
+			request_id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, request_id)
+		ctx := context.WithValue(req.Context(), requestIDKey, request_id)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+func requestID(req *http.Request) string {
+	request_id, _ := req.Context().Value(requestIDKey).(string)
+	return request_id
+}
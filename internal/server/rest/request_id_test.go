@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"proxy id", "abc-123_DEF.4", true},
+		{"longest", strings.Repeat("a", 64), true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", 65), false},
+		{"spaces", "abc 123", false},
+		{"log injection", "abc\nFAKE LOG LINE", false},
+		{"html", "<script>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header[requestIDHeader] = []string{tt.header}
+			}
+			w := httptest.NewRecorder()
+			Rest{}.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				seen = requestID(req)
+			})).ServeHTTP(w, req)
+			if got := w.Header().Get(requestIDHeader); got != seen {
+				t.Errorf("sent request id %q, handler saw %q", got, seen)
+			}
+			if tt.keep && seen != tt.header {
+				t.Errorf("got request id %q, want %q", seen, tt.header)
+			}
+			if !tt.keep && (seen == tt.header || !validRequestID.MatchString(seen)) {
+				t.Errorf("request id %q was not replaced, got %q", tt.header, seen)
+			}
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	first, second := newRequestID(), newRequestID()
+	if !validRequestID.MatchString(first) || len(first) != 16 {
+		t.Errorf("invalid request id %q", first)
+	}
+	if first == second {
+		t.Errorf("request ids repeat: %q", first)
+	}
+}
+
+func TestRequestIDWithoutMiddleware(t *testing.T) {
+	if got := requestID(httptest.NewRequest("GET", "/", nil)); got != "" {
+		t.Errorf("got request id %q without the middleware", got)
+	}
+}
@@ -1,18 +1,54 @@
 package rest
 
 import (
+	"errors"
+	"html/template"
+	"io/fs"
+	"time"
+
 	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
 	"gopkg.in/go-playground/validator.v9"
 )
 
+const defaultSessionTTL time.Duration = 24 * time.Hour
+
 type Rest struct {
-	validator *validator.Validate
-	processor processors.Processor
+	cfg        Config
+	sessionTTL time.Duration
+	validator  *validator.Validate
+	processor  processors.Processor
+	files      fs.FS
+	static     fs.FS
+	templates  map[string]*template.Template
 }
 
-func New(v *validator.Validate, p processors.Processor) Rest {
-	return Rest{
-		validator: v,
-		processor: p,
+func New(cfg Config, v *validator.Validate, p processors.Processor) (*Rest, error) {
+	ttl := defaultSessionTTL
+	if cfg.SessionTTL != "" {
+		d, err := time.ParseDuration(cfg.SessionTTL)
+		if err != nil {
+			msg := "invalid session_ttl '" + cfg.SessionTTL + "': " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ttl = d
+	}
+	files := webFiles(cfg.Dev)
+	templates, err := parseTemplates(files)
+	if err != nil {
+		return nil, err
+	}
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		msg := "cannot open static files: " + err.Error()
+		return nil, errors.New(msg)
 	}
+	return &Rest{
+		cfg:        cfg,
+		sessionTTL: ttl,
+		validator:  v,
+		processor:  p,
+		files:      files,
+		static:     static,
+		templates:  templates,
+	}, nil
 }
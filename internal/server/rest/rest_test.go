@@ -0,0 +1,80 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// newTestRest returns a Rest over a migrated database in a temporary
+// directory, which only has the admin user.
+func newTestRest(t *testing.T) (*Rest, *database.Database) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Database files are opened relative to the working directory
+	file, err := filepath.Rel(wd, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, sqlxDB, err := database.New(database.Config{File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	prcr := processors.New(processors.Config{Directory: t.TempDir()}, db)
+	r, err := New(Config{Host: "127.0.0.1", Port: "0"}, validator.New(), prcr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, db
+}
+
+func testUser(t *testing.T, db *database.Database, username string) *database.User {
+	t.Helper()
+	u := user.New(apierror.MethodHTTP)
+	u.Username = username
+	u.Firstname = "First"
+	u.Lastname = "Last"
+	db_user, err := db.CreateUser(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db_user
+}
+
+func testAdmin(t *testing.T, db *database.Database) *database.User {
+	t.Helper()
+	admin, err := db.GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin == nil || !admin.IsAdmin() {
+		t.Fatal("migrations did not create an admin user")
+	}
+	return admin
+}
+
+// testRequest is a request made by u, with the route variables vars.
+func testRequest(method string, target string, body string, u *database.User, vars map[string]string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), authorKey, u))
+	return mux.SetURLVars(req, vars)
+}
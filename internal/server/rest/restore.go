@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func (r Rest) RestoreHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_history_id, err := strconv.Atoi(vars["post_history_id"])
+		if err != nil {
+			msg := "invalid post_history_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.Restore(int64(post_history_id), method)
+		if apiErr != nil {
+			msg := "Error processing restore request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Restored post history")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
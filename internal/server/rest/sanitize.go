@@ -0,0 +1,182 @@
+package rest
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sanitizeElements are the elements a body may keep, with the attributes each
+// of them may keep besides sanitizeGlobalAttrs. They are what the editor
+// produces along with the markup of imported posts.
+var sanitizeElements = map[string][]string{
+	"a":          {"href"},
+	"abbr":       {},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"caption":    {},
+	"code":       {},
+	"del":        {},
+	"div":        {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"font":       {"color", "face", "size"},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src", "alt", "width", "height"},
+	"ins":        {},
+	"li":         {},
+	"ol":         {"start"},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"small":      {},
+	"span":       {},
+	"strike":     {},
+	"strong":     {},
+	"sub":        {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      {},
+	"th":         {"colspan", "rowspan"},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+var sanitizeGlobalAttrs = []string{"style", "title"}
+
+// sanitizeDropped are removed along with everything inside them. Other
+// elements that are not allowed are replaced by their content.
+var sanitizeDropped = map[string]bool{
+	"embed":    true,
+	"form":     true,
+	"iframe":   true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"select":   true,
+	"style":    true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+var sanitizeVoid = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+// sanitizeHTML returns body with only the elements and attributes above, so
+// that a stored revision can be shown as HTML. It is the only source of
+// template.HTML in the pages.
+func sanitizeHTML(body string) template.HTML {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(body), context)
+	if err != nil {
+		return template.HTML(template.HTMLEscapeString(body))
+	}
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		writeSanitized(&buf, n)
+	}
+	return template.HTML(buf.String())
+}
+
+func writeSanitized(buf *bytes.Buffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes are dropped
+		return
+	}
+	if sanitizeDropped[n.Data] {
+		return
+	}
+	attrs, ok := sanitizeElements[n.Data]
+	if ok {
+		buf.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			if a.Namespace == "" && allowedAttr(a, attrs) {
+				buf.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+			}
+		}
+		buf.WriteString(">")
+		if sanitizeVoid[n.Data] {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeSanitized(buf, c)
+	}
+	if ok {
+		buf.WriteString("</" + n.Data + ">")
+	}
+}
+
+func allowedAttr(a html.Attribute, attrs []string) bool {
+	if !hasKey(attrs, a.Key) && !hasKey(sanitizeGlobalAttrs, a.Key) {
+		return false
+	}
+	switch a.Key {
+	case "href", "src":
+		return safeURL(a.Val)
+	case "style":
+		return safeStyle(a.Val)
+	}
+	return true
+}
+
+func hasKey(keys []string, key string) bool {
+	for i := range keys {
+		if keys[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// safeURL allows links relative to the site, such as uploaded assets, and
+// web and mail links.
+func safeURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// safeStyle allows the inline styles the editor formats text with, but none
+// that load resources or run script.
+func safeStyle(s string) bool {
+	style := strings.ToLower(s)
+	for _, bad := range []string{"url(", "expression", "javascript:", "@import", "\\"} {
+		if strings.Contains(style, bad) {
+			return false
+		}
+	}
+	return true
+}
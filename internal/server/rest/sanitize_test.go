@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"text", `a < b & c`, `a &lt; b &amp; c`},
+		{"allowed markup", `<p><b>bold</b> <em>em</em></p><ul><li>one</li></ul>`, `<p><b>bold</b> <em>em</em></p><ul><li>one</li></ul>`},
+		{"script", `<p>x</p><script>alert(1)</script>`, `<p>x</p>`},
+		{"closing script", `</script><script>alert(1)</script>`, ``},
+		{"event handler", `"><img src=x onerror=alert(1)>`, `&#34;&gt;<img src="x">`},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"mixed case javascript link", `<a href=" JaVaScRiPt:alert(1)">x</a>`, `<a>x</a>`},
+		{"encoded javascript link", `<a href="java&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"data image", `<img src="data:text/html,<script>alert(1)</script>">`, `<img>`},
+		{"web link", `<a href="https://example.com/?a=1&b=2" title="t">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" title="t">x</a>`},
+		{"relative image", `<img src="/assets/1.png" alt="one">`, `<img src="/assets/1.png" alt="one">`},
+		{"mail link", `<a href="mailto:me@example.com">me</a>`, `<a href="mailto:me@example.com">me</a>`},
+		{"style", `<span style="color: red">x</span>`, `<span style="color: red">x</span>`},
+		{"style url", `<span style="background: url(https://example.com/x)">x</span>`, `<span>x</span>`},
+		{"style expression", `<span style="width: expression(alert(1))">x</span>`, `<span>x</span>`},
+		{"style escape", `<span style="background: u\72l(x)">x</span>`, `<span>x</span>`},
+		{"attribute not allowed", `<p class="x" id="y" onclick="alert(1)">x</p>`, `<p>x</p>`},
+		{"iframe", `<iframe src="https://example.com"><p>x</p></iframe>y`, `y`},
+		{"style element", `<style>body { display: none }</style>y`, `y`},
+		{"unknown element", `<article><p>x</p></article>`, `<p>x</p>`},
+		{"svg", `<svg onload="alert(1)"><a href="javascript:alert(1)">x</a></svg>`, `<a>x</a>`},
+		{"comment", `<!-- <script>alert(1)</script> -->x`, `x`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(sanitizeHTML(tt.body))
+			if got != tt.want {
+				t.Errorf("sanitizeHTML(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -16,24 +17,31 @@ func (r Rest) SaveHandler(w http.ResponseWriter, req *http.Request) {
 		if err != nil {
 			msg := "Error reading save request data: " + err.Error()
 			log.Println(msg)
-			http.Error(w, msg, http.StatusInternalServerError)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
 			return
 		}
-		post := post.New(apierror.MethodHTTP)
+		post := post.New(apierror.MethodHTTP, authorName(req))
 		if err := json.Unmarshal(data, &post); err != nil {
 			msg := "Error marshalling save json data: " + err.Error()
 			log.Println(msg)
-			http.Error(w, msg, http.StatusInternalServerError)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
 			return
 		}
-		if apiErr := r.processor.SaveForm(post); apiErr != nil {
+		revision, apiErr := r.processor.SaveForm(post)
+		if apiErr != nil {
 			msg := "Error processing save request: " + apiErr.Error()
 			log.Println(msg)
-			http.Error(w, msg, apiErr.Code())
+			if apiErr.Status() == apierror.StatusConflict {
+				writeConflict(w, req, msg, apiErr, revision)
+				return
+			}
+			writeError(w, req, msg, apiErr)
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
 		log.Println("Saved post")
+		writeJSON(w, http.StatusCreated, revision)
 		return
 	}
 }
@@ -0,0 +1,59 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSaveHandlerConflict(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+	save := func(body string, base int64) *httptest.ResponseRecorder {
+		payload := `{"title":"Saved Post","tags":["test"],"categories":["testing"],"body":"` + body + `","post_history_id":` + strconv.FormatInt(base, 10) + `}`
+		w := httptest.NewRecorder()
+		r.SaveHandler(w, testRequest("POST", "/api/save", payload, admin, nil))
+		return w
+	}
+	type revision struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	w := save("first", 0)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var first revision
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+	w = save("second", first.ID)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var second revision
+	if err := json.Unmarshal(w.Body.Bytes(), &second); err != nil {
+		t.Fatal(err)
+	}
+	w = save("stale", first.ID)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusConflict, w.Body.String())
+	}
+	var conflict struct {
+		Status   string    `json:"status"`
+		Revision *revision `json:"revision"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &conflict); err != nil {
+		t.Fatal(err)
+	}
+	if conflict.Status != "CONFLICT" {
+		t.Errorf("got status %q, want CONFLICT", conflict.Status)
+	}
+	if conflict.Revision == nil || *conflict.Revision != second {
+		t.Errorf("got revision %+v, want the latest revision %+v", conflict.Revision, second)
+	}
+}
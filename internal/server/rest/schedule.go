@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+type scheduleRequest struct {
+	PublishAt int64 `json:"publish_at"`
+}
+
+func (r Rest) SchedulePostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "PUT" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading schedule request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		var s scheduleRequest
+		if err := json.Unmarshal(data, &s); err != nil {
+			msg := "Error marshalling schedule json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.SchedulePost(int64(post_id), s.PublishAt, method)
+		if apiErr != nil {
+			msg := "Error processing schedule request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Scheduled post")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
+
+func (r Rest) UnschedulePostHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "DELETE" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		post_id, err := strconv.Atoi(vars["post_id"])
+		if err != nil {
+			msg := "invalid post_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		complete_post, apiErr := r.processor.UnschedulePost(int64(post_id), method)
+		if apiErr != nil {
+			msg := "Error processing unschedule request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Unscheduled post")
+		writeJSON(w, http.StatusOK, complete_post)
+	}
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// SearchHandler searches posts with the q query parameter. Results can be
+// narrowed with repeated tag and category parameters and with posted=true or
+// posted=false.
+func (r Rest) SearchHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		method := apierror.MethodHTTP
+		params := req.URL.Query()
+		filters := database.SearchFilters{
+			Tags:       params["tag"],
+			Categories: params["category"],
+		}
+		if posted := params.Get("posted"); posted != "" {
+			b, err := strconv.ParseBool(posted)
+			if err != nil {
+				msg := "invalid posted in query: " + err.Error()
+				log.Println(msg)
+				apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+				writeError(w, req, msg, apiErr)
+				return
+			}
+			p := database.DB_FALSE()
+			if b {
+				p = database.DB_TRUE()
+			}
+			filters.Posted = &p
+		}
+		results, apiErr := r.processor.Search(params.Get("q"), filters, method)
+		if apiErr != nil {
+			msg := "Error searching posts: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, results)
+	}
+}
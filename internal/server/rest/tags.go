@@ -0,0 +1,21 @@
+package rest
+
+import (
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func (r Rest) TagsHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		tags, apiErr := r.processor.Tags(apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error gathering tags: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+
+	"gitlab.com/joshraphael/motdoftheday"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+// pages are the templates in templates/ that handlers render.
+var pages = []string{"home.html", "login.html", "drafts.html", "draft.html", "edit.html"}
+
+// templateFuncs are available to every page. Values are escaped for where
+// they appear in a page, except bodies passed through sanitize.
+var templateFuncs = template.FuncMap{
+	"sanitize": sanitizeHTML,
+}
+
+// webFiles are the templates and static files, read from the working
+// directory in dev mode so they can be edited without a rebuild.
+func webFiles(dev bool) fs.FS {
+	if dev {
+		return os.DirFS(".")
+	}
+	return motdoftheday.Web
+}
+
+// parseTemplates parses every page in files, keyed by its file name.
+func parseTemplates(files fs.FS) (map[string]*template.Template, error) {
+	templates := map[string]*template.Template{}
+	for _, name := range pages {
+		tmpl, err := parseTemplate(files, name)
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+func parseTemplate(files fs.FS, name string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).ParseFS(files, "templates/"+name)
+	if err != nil {
+		msg := "cannot parse template " + name + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return tmpl, nil
+}
+
+// render writes the page name executed with data. The page is executed into a
+// buffer first, so that a page that fails part way is sent as an error rather
+// than half written. Dev mode parses the page from disk again on every
+// request.
+func (r Rest) render(w http.ResponseWriter, req *http.Request, name string, data interface{}) {
+	tmpl := r.templates[name]
+	if r.cfg.Dev {
+		reloaded, err := parseTemplate(r.files, name)
+		if err != nil {
+			msg := "Error reloading template: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		tmpl = reloaded
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		msg := "Error rendering " + name + ": " + err.Error()
+		log.Println(msg)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+		writeError(w, req, msg, apiErr)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// StaticHandler serves the files in static/.
+func (r Rest) StaticHandler() http.Handler {
+	return http.FileServer(http.FS(r.static))
+}
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// TestPagesEscapePosts renders the draft and edit pages of a post whose
+// title, tags, categories and body try to break out of where they are
+// written. Titles, tags and categories are validated before they are saved or
+// imported, so only bodies can hold these today, but the pages must not rely
+// on that. forbidden are what the payload would look like on the page, as
+// HTML or inside the editor's JavaScript string, had it not been escaped or
+// sanitized.
+func TestPagesEscapePosts(t *testing.T) {
+	r, err := New(Config{}, validator.New(), processors.Processor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		payload   string
+		forbidden []string
+	}{
+		{
+			name:      "closing script",
+			payload:   `</script><script>alert(1)</script>`,
+			forbidden: []string{`<script>alert(1)`, `\u003cscript\u003ealert(1)`},
+		},
+		{
+			name:      "attribute breakout",
+			payload:   `"><img src=x onerror=alert(1)>`,
+			forbidden: []string{`"><img`, `onerror=alert(1)>`, `onerror=alert(1)\u003e`},
+		},
+		{
+			name:      "javascript link",
+			payload:   `<a href="javascript:alert(1)">x</a>`,
+			forbidden: []string{`href="javascript:`, `href=\"javascript:`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db_post := &database.Post{ID: 1, UrlTitle: tt.payload, Title: tt.payload, PublishedName: tt.payload}
+			history := database.PostHistory{ID: 2, PostID: 1, Body: tt.payload}
+			tags := []database.Tag{{ID: 3, Name: tt.payload}}
+			categories := []database.Category{{ID: 4, Name: tt.payload}}
+			pages := []struct {
+				name string
+				data interface{}
+			}{
+				{"draft.html", &database.CompletePost{
+					Post:       db_post,
+					History:    []database.PostHistory{history},
+					Tags:       map[int64][]database.Tag{history.ID: tags},
+					Categories: map[int64][]database.Category{history.ID: categories},
+				}},
+				{"edit.html", &database.CompletePostHistory{
+					Post:       db_post,
+					History:    &history,
+					Tags:       tags,
+					Categories: categories,
+				}},
+			}
+			for _, page := range pages {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", "/", nil)
+				r.render(w, req, page.name, page.data)
+				body := w.Body.String()
+				if !strings.Contains(body, "</html>") {
+					t.Fatalf("%s was not rendered:\n%s", page.name, body)
+				}
+				for _, f := range tt.forbidden {
+					if strings.Contains(body, f) {
+						t.Errorf("%s contains %q:\n%s", page.name, f, body)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestParseEmbeddedPages parses every page from the files built into the
+// binary, which is what the server runs with outside dev mode.
+func TestParseEmbeddedPages(t *testing.T) {
+	templates, err := parseTemplates(motdoftheday.Web)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range pages {
+		if templates[name] == nil {
+			t.Errorf("page %s was not parsed", name)
+		}
+	}
+	// A page added to templates/ but not to pages would not be parsed
+	files, err := fs.Glob(motdoftheday.Web, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if templates[path.Base(file)] == nil {
+			t.Errorf("%s is not in pages", file)
+		}
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	r, err := New(Config{}, validator.New(), processors.Processor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	// A page executed with data it cannot read fails part way through
+	r.render(w, req, "draft.html", 42)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q, want application/json", ct)
+	}
+	var envelope apierror.Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("error is not an envelope: %v\n%s", err, w.Body.String())
+	}
+	if envelope.Status != string(apierror.StatusInternal) || !strings.Contains(envelope.Message, "draft.html") {
+		t.Errorf("got error %+v", envelope)
+	}
+}
+
+func TestRender(t *testing.T) {
+	r, err := New(Config{}, validator.New(), processors.Processor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	r.render(w, req, "login.html", nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("got content type %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "</html>") {
+		t.Errorf("login.html was not rendered:\n%s", w.Body.String())
+	}
+}
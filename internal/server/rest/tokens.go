@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+type apiTokenRequest struct {
+	Name string `json:"name"`
+}
+
+type apiTokenResponse struct {
+	Token    string             `json:"token"`
+	ApiToken *database.ApiToken `json:"api_token"`
+}
+
+func (r Rest) ApiTokensHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		tokens, apiErr := r.processor.ApiTokens(author(req), apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error gathering api tokens: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, tokens)
+	}
+}
+
+func (r Rest) CreateApiTokenHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading api token request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		var tokenReq apiTokenRequest
+		if err := json.Unmarshal(data, &tokenReq); err != nil {
+			msg := "Error marshalling api token json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		token, api_token, apiErr := r.processor.CreateApiToken(author(req), tokenReq.Name, apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error processing api token request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Created api token")
+		writeJSON(w, http.StatusCreated, apiTokenResponse{
+			Token:    token,
+			ApiToken: api_token,
+		})
+	}
+}
+
+func (r Rest) DeleteApiTokenHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "DELETE" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		token_id, err := strconv.Atoi(vars["token_id"])
+		if err != nil {
+			msg := "invalid token_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if apiErr := r.processor.DeleteApiToken(author(req), int64(token_id), method); apiErr != nil {
+			msg := "Error processing delete api token request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		log.Println("Deleted api token")
+	}
+}
@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+)
+
+func (r Rest) UserHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		user_id, err := strconv.Atoi(vars["user_id"])
+		if err != nil {
+			msg := "invalid user_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		db_user, apiErr := r.processor.GetUser(int64(user_id), method)
+		if apiErr != nil {
+			msg := "Error gathering user: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, db_user)
+	}
+}
+
+func (r Rest) UpdateUserHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "PUT" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		user_id, err := strconv.Atoi(vars["user_id"])
+		if err != nil {
+			msg := "invalid user_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if !selfOrAdmin(req, int64(user_id)) {
+			msg := "cannot update another user"
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusForbidden, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading update user request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		u := user.New(method)
+		if err := json.Unmarshal(data, &u); err != nil {
+			msg := "Error marshalling update user json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		db_user, apiErr := r.processor.UpdateUser(int64(user_id), u)
+		if apiErr != nil {
+			msg := "Error processing update user request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Updated user")
+		writeJSON(w, http.StatusOK, db_user)
+	}
+}
+
+func (r Rest) DeleteUserHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "DELETE" {
+		vars := mux.Vars(req)
+		method := apierror.MethodHTTP
+		user_id, err := strconv.Atoi(vars["user_id"])
+		if err != nil {
+			msg := "invalid user_id in url: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if !selfOrAdmin(req, int64(user_id)) {
+			msg := "cannot delete another user"
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusForbidden, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		if apiErr := r.processor.DeleteUser(int64(user_id), method); apiErr != nil {
+			msg := "Error processing delete user request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		log.Println("Deleted user")
+	}
+}
@@ -0,0 +1,61 @@
+//go:build sqlite_fts5
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func TestUserHandlersRequireSelfOrAdmin(t *testing.T) {
+	r, db := newTestRest(t)
+	admin := testAdmin(t, db)
+	alice := testUser(t, db, "alice")
+	bob := testUser(t, db, "bob")
+	carol := testUser(t, db, "carol")
+	id := func(id int64) map[string]string {
+		return map[string]string{"user_id": strconv.FormatInt(id, 10)}
+	}
+	update := func(u *database.User) string {
+		return `{"user_name":"` + u.Username + `","first_name":"New","last_name":"Name"}`
+	}
+	password := `{"password":"correct horse battery"}`
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		req     *http.Request
+		code    int
+	}{
+		{"author creates user", r.CreateUserHandler, testRequest("POST", "/api/users", `{"user_name":"dave","first_name":"D","last_name":"D"}`, alice, nil), http.StatusForbidden},
+		{"admin creates user", r.CreateUserHandler, testRequest("POST", "/api/users", `{"user_name":"erin","first_name":"E","last_name":"E"}`, admin, nil), http.StatusCreated},
+		{"author updates other user", r.UpdateUserHandler, testRequest("PUT", "/api/users", update(bob), alice, id(bob.ID)), http.StatusForbidden},
+		{"author updates self", r.UpdateUserHandler, testRequest("PUT", "/api/users", update(alice), alice, id(alice.ID)), http.StatusOK},
+		{"admin updates other user", r.UpdateUserHandler, testRequest("PUT", "/api/users", update(bob), admin, id(bob.ID)), http.StatusOK},
+		{"author sets password of other user", r.PasswordHandler, testRequest("PUT", "/api/users", password, alice, id(bob.ID)), http.StatusForbidden},
+		{"author sets own password", r.PasswordHandler, testRequest("PUT", "/api/users", password, alice, id(alice.ID)), http.StatusNoContent},
+		{"admin sets password of other user", r.PasswordHandler, testRequest("PUT", "/api/users", password, admin, id(bob.ID)), http.StatusNoContent},
+		{"author deletes other user", r.DeleteUserHandler, testRequest("DELETE", "/api/users", "", alice, id(bob.ID)), http.StatusForbidden},
+		{"admin deletes other user", r.DeleteUserHandler, testRequest("DELETE", "/api/users", "", admin, id(bob.ID)), http.StatusNoContent},
+		{"author deletes self", r.DeleteUserHandler, testRequest("DELETE", "/api/users", "", carol, id(carol.ID)), http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, tt.req)
+			if w.Code != tt.code {
+				t.Errorf("got status %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+		})
+	}
+	db_bob, err := db.GetUserById(bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db_bob != nil {
+		t.Error("bob was not deleted by the admin")
+	}
+}
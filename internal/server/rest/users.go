@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+)
+
+func (r Rest) UsersHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "GET" {
+		users, apiErr := r.processor.Users(apierror.MethodHTTP)
+		if apiErr != nil {
+			msg := "Error gathering users: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		writeJSON(w, http.StatusOK, users)
+	}
+}
+
+// CreateUserHandler adds a user, which only admins may do.
+func (r Rest) CreateUserHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method == "POST" {
+		method := apierror.MethodHTTP
+		if !isAdmin(req) {
+			msg := "only admins can create users"
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusForbidden, method)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			msg := "Error reading create user request data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		u := user.New(apierror.MethodHTTP)
+		if err := json.Unmarshal(data, &u); err != nil {
+			msg := "Error marshalling create user json data: " + err.Error()
+			log.Println(msg)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, apierror.MethodHTTP)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		db_user, apiErr := r.processor.CreateUser(u)
+		if apiErr != nil {
+			msg := "Error processing create user request: " + apiErr.Error()
+			log.Println(msg)
+			writeError(w, req, msg, apiErr)
+			return
+		}
+		log.Println("Created user")
+		writeJSON(w, http.StatusCreated, db_user)
+	}
+}
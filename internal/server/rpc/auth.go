@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type contextKey string
+
+const authorKey contextKey = "author"
+
+// AuthInterceptor requires an "authorization: Bearer" API token or session
+// token in the metadata of every call.
+func (r Rpc) AuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	user, apiErr := r.processor.Authenticate(requestToken(ctx), apierror.MethodGRPC)
+	if apiErr != nil {
+		msg := "Error authenticating " + info.FullMethod + ": " + apiErr.Error()
+		log.Println(msg)
+		return nil, statusError(msg, apiErr)
+	}
+	return handler(context.WithValue(ctx, authorKey, user), req)
+}
+
+func requestToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+}
+
+func author(ctx context.Context) *database.User {
+	user, _ := ctx.Value(authorKey).(*database.User)
+	return user
+}
+
+func authorName(ctx context.Context) string {
+	if user := author(ctx); user != nil {
+		return user.Username
+	}
+	return ""
+}
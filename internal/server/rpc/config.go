@@ -0,0 +1,8 @@
+package rpc
+
+// Config is where the gRPC server listens. The server is not started when
+// Port is empty.
+type Config struct {
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// newPost is the post in a request, written by the caller.
+func newPost(pb *motdpb.Post, author string) post.Post {
+	p := post.New(apierror.MethodGRPC, author)
+	if pb == nil {
+		return p
+	}
+	p.Title = pb.Title
+	p.Categories = pb.Categories
+	p.Tags = pb.Tags
+	p.Body = pb.Body
+	p.Format = pb.Format
+	p.PostHistoryID = pb.PostHistoryId
+	return p
+}
+
+func postInfo(p *database.Post) *motdpb.PostInfo {
+	if p == nil {
+		return nil
+	}
+	return &motdpb.PostInfo{
+		Id:            p.ID,
+		UrlTitle:      p.UrlTitle,
+		UserId:        p.UserID,
+		Title:         p.Title,
+		Posted:        p.Posted == database.DB_TRUE().Value(),
+		Format:        p.Format,
+		PublishAt:     p.PublishAt,
+		PublishedName: p.PublishedName,
+		UpdateTime:    p.UpdateTime,
+		InsertTime:    p.InsertTime,
+	}
+}
+
+func revisionInfo(h *database.PostHistory, categories []database.Category, tags []database.Tag) *motdpb.Revision {
+	if h == nil {
+		return nil
+	}
+	rev := &motdpb.Revision{
+		Id:         h.ID,
+		PostId:     h.PostID,
+		Body:       h.Body,
+		Method:     h.Method,
+		InsertTime: h.InsertTime,
+		Categories: []*motdpb.Category{},
+		Tags:       []*motdpb.Tag{},
+	}
+	for i := range categories {
+		rev.Categories = append(rev.Categories, &motdpb.Category{Id: categories[i].ID, Name: categories[i].Name})
+	}
+	for i := range tags {
+		rev.Tags = append(rev.Tags, &motdpb.Tag{Id: tags[i].ID, Name: tags[i].Name})
+	}
+	return rev
+}
+
+func postRevisionInfo(p *database.CompletePostHistory) *motdpb.PostRevision {
+	return &motdpb.PostRevision{
+		Post:     postInfo(p.Post),
+		Revision: revisionInfo(p.History, p.Categories, p.Tags),
+	}
+}
+
+func draftInfo(p *database.CompletePost) *motdpb.Draft {
+	d := &motdpb.Draft{
+		Post:    postInfo(p.Post),
+		History: []*motdpb.Revision{},
+	}
+	for i := range p.History {
+		h := p.History[i]
+		d.History = append(d.History, revisionInfo(&h, p.Categories[h.ID], p.Tags[h.ID]))
+	}
+	return d
+}
@@ -0,0 +1,19 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) GetDraft(ctx context.Context, req *motdpb.GetDraftRequest) (*motdpb.Draft, error) {
+	p, apiErr := r.processor.Draft(req.GetPostId(), apierror.MethodGRPC)
+	if apiErr != nil {
+		msg := "Error gathering draft post: " + apiErr.Error()
+		log.Println(msg)
+		return nil, statusError(msg, apiErr)
+	}
+	return draftInfo(p), nil
+}
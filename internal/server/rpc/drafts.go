@@ -0,0 +1,25 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) ListDrafts(ctx context.Context, req *motdpb.ListDraftsRequest) (*motdpb.ListDraftsResponse, error) {
+	posts, apiErr := r.processor.Drafts(apierror.MethodGRPC)
+	if apiErr != nil {
+		msg := "Error gathering draft posts: " + apiErr.Error()
+		log.Println(msg)
+		return nil, statusError(msg, apiErr)
+	}
+	resp := &motdpb.ListDraftsResponse{
+		Drafts: []*motdpb.PostInfo{},
+	}
+	for i := range posts {
+		resp.Drafts = append(resp.Drafts, postInfo(&posts[i]))
+	}
+	return resp, nil
+}
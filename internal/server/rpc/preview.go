@@ -0,0 +1,21 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) Preview(ctx context.Context, req *motdpb.PreviewRequest) (*motdpb.PreviewResponse, error) {
+	rendered, apiErr := r.processor.Preview(newPost(req.GetPost(), authorName(ctx)))
+	if apiErr != nil {
+		msg := "Error processing preview request: " + apiErr.Error()
+		log.Println(msg)
+		return nil, statusError(msg, apiErr)
+	}
+	return &motdpb.PreviewResponse{
+		Name:    rendered.Name,
+		Content: rendered.Content,
+	}, nil
+}
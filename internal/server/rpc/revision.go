@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) GetRevision(ctx context.Context, req *motdpb.GetRevisionRequest) (*motdpb.PostRevision, error) {
+	return r.postRevision(req.GetPostHistoryId())
+}
@@ -0,0 +1,20 @@
+package rpc
+
+import (
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+// Rpc implements the MotdService over the same processor as the REST server.
+type Rpc struct {
+	motdpb.UnimplementedMotdServiceServer
+	cfg       Config
+	processor processors.Processor
+}
+
+func New(cfg Config, p processors.Processor) *Rpc {
+	return &Rpc{
+		cfg:       cfg,
+		processor: p,
+	}
+}
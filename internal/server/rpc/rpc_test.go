@@ -0,0 +1,147 @@
+//go:build sqlite_fts5
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the MotdService over a migrated database in a
+// temporary directory, which only has the admin user, and returns a client
+// of it along with an API token of the admin.
+func newTestClient(t *testing.T) (motdpb.MotdServiceClient, string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Database files are opened relative to the working directory
+	file, err := filepath.Rel(wd, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, sqlxDB, err := database.New(database.Config{File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	prcr := processors.New(processors.Config{Directory: t.TempDir()}, db)
+	admin, err := db.GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, apiErr := prcr.CreateApiToken(admin, "test", apierror.MethodGRPC)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	rpcHandler := New(Config{}, prcr)
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.UnaryInterceptor(rpcHandler.AuthInterceptor))
+	motdpb.RegisterMotdServiceServer(s, rpcHandler)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+	conn, err := grpc.NewClient("passthrough:///bufconn", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return motdpb.NewMotdServiceClient(conn), token
+}
+
+// withToken returns a context that sends authorization with every call.
+func withToken(authorization string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", authorization)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client, token := newTestClient(t)
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"no token", context.Background(), codes.Unauthenticated},
+		{"empty token", withToken("Bearer "), codes.Unauthenticated},
+		{"bad token", withToken("Bearer not-a-token"), codes.Unauthenticated},
+		{"not bearer", withToken("Basic " + token), codes.Unauthenticated},
+		{"api token", withToken("Bearer " + token), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ListDrafts(tt.ctx, &motdpb.ListDraftsRequest{})
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("got code %s, want %s: %v", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestSavePost(t *testing.T) {
+	client, token := newTestClient(t)
+	ctx := withToken("Bearer " + token)
+	save := func(body string, base int64) (*motdpb.PostRevision, error) {
+		return client.SavePost(ctx, &motdpb.SavePostRequest{Post: &motdpb.Post{
+			Title:         "Saved Post",
+			Categories:    []string{"testing"},
+			Tags:          []string{"test"},
+			Body:          body,
+			PostHistoryId: &base,
+		}})
+	}
+	first, err := save("<p>first</p>", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetPost().GetTitle() != "Saved Post" || first.GetRevision().GetBody() != "<p>first</p>" {
+		t.Errorf("saved %v", first)
+	}
+	second, err := save("<p>second</p>", first.GetRevision().GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = save("<p>stale</p>", first.GetRevision().GetId())
+	st := status.Convert(err)
+	if st.Code() != codes.Aborted {
+		t.Fatalf("got code %s for a stale revision, want %s: %v", st.Code(), codes.Aborted, err)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("got details %v, want the newer revision", details)
+	}
+	latest, ok := details[0].(*motdpb.PostRevision)
+	if !ok {
+		t.Fatalf("detail is %T, want a PostRevision", details[0])
+	}
+	if latest.GetRevision().GetId() != second.GetRevision().GetId() || latest.GetRevision().GetBody() != "<p>second</p>" {
+		t.Errorf("got revision %v along with the conflict, want %v", latest.GetRevision(), second.GetRevision())
+	}
+
+	_, err = client.SavePost(ctx, &motdpb.SavePostRequest{Post: &motdpb.Post{Title: "Invalid/Title", Body: "<p>body</p>"}})
+	st = status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Errorf("got %s with details %v for an invalid post, want %s with the field errors", st.Code(), st.Details(), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) SavePost(ctx context.Context, req *motdpb.SavePostRequest) (*motdpb.PostRevision, error) {
+	revision, apiErr := r.processor.SaveForm(newPost(req.GetPost(), authorName(ctx)))
+	if apiErr != nil {
+		msg := "Error processing save request: " + apiErr.Error()
+		log.Println(msg)
+		return nil, r.saveError(msg, apiErr, revision)
+	}
+	log.Println("Saved post")
+	return r.postRevision(revision.ID)
+}
+
+// saveError is the status of a refused save. A CONFLICT carries the newer
+// revision of the post as a detail, like the REST server sends it along.
+func (r Rpc) saveError(msg string, apiErr apierror.IApiError, latest *database.PostHistory) error {
+	if apiErr.Status() != apierror.StatusConflict || latest == nil {
+		return statusError(msg, apiErr)
+	}
+	p, latestErr := r.processor.Edit(latest.ID, apierror.MethodGRPC)
+	if latestErr != nil {
+		log.Println("Error getting conflicting revision: " + latestErr.Error())
+		return statusError(msg, apiErr)
+	}
+	return statusError(msg, apiErr, postRevisionInfo(p))
+}
+
+// postRevision returns a saved revision along with its post.
+func (r Rpc) postRevision(post_history_id int64) (*motdpb.PostRevision, error) {
+	p, apiErr := r.processor.Edit(post_history_id, apierror.MethodGRPC)
+	if apiErr != nil {
+		msg := "Error getting revision: " + apiErr.Error()
+		log.Println(msg)
+		return nil, statusError(msg, apiErr)
+	}
+	return postRevisionInfo(p), nil
+}
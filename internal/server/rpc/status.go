@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// statusError is the gRPC status apiErr is sent to clients as, carrying the
+// gRPC code of its apierror status. Field errors are sent as BadRequest
+// details, followed by any extra details.
+func statusError(msg string, apiErr apierror.IApiError, details ...protoadapt.MessageV1) error {
+	st := status.New(codes.Code(apiErr.Code()), msg)
+	fields := apiErr.Fields()
+	if len(fields) > 0 {
+		violations := []*errdetails.BadRequest_FieldViolation{}
+		for i := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fields[i].Field,
+				Description: fields[i].Message,
+			})
+		}
+		details = append([]protoadapt.MessageV1{&errdetails.BadRequest{FieldViolations: violations}}, details...)
+	}
+	if len(details) == 0 {
+		return st.Err()
+	}
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		log.Println("Error adding details to " + string(apiErr.Status()) + " status: " + err.Error())
+		return st.Err()
+	}
+	return detailed.Err()
+}
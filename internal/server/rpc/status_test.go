@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	p := post.New(apierror.MethodGRPC, "admin")
+	p.Title = "Invalid/Title"
+	p.Tags = []string{"test"}
+	p.Body = "<p>body</p>"
+	err := p.Validate()
+	if err == nil {
+		t.Fatal("invalid post was validated")
+	}
+	apiErr := apierror.New(fmt.Errorf("invalid post: %w", err), apierror.StatusBadRequest, apierror.MethodGRPC)
+	revision := &motdpb.PostRevision{Post: &motdpb.PostInfo{Id: 1}}
+	st, ok := status.FromError(statusError("Error saving post", apiErr, revision))
+	if !ok {
+		t.Fatal("error is not a gRPC status")
+	}
+	if st.Code() != codes.InvalidArgument || st.Message() != "Error saving post" {
+		t.Errorf("got status %s %q", st.Code(), st.Message())
+	}
+	details := st.Details()
+	if len(details) != 2 {
+		t.Fatalf("got details %v, want the field errors and the revision", details)
+	}
+	bad_request, ok := details[0].(*errdetails.BadRequest)
+	if !ok {
+		t.Fatalf("first detail is %T, want BadRequest", details[0])
+	}
+	want := map[string]bool{"title": true, "categories": true}
+	violations := bad_request.GetFieldViolations()
+	if len(violations) != len(want) {
+		t.Fatalf("got field violations %v, want %v", violations, want)
+	}
+	for i := range violations {
+		if !want[violations[i].GetField()] || violations[i].GetDescription() == "" {
+			t.Errorf("got field violation %v", violations[i])
+		}
+	}
+	if got, ok := details[1].(*motdpb.PostRevision); !ok || got.GetPost().GetId() != 1 {
+		t.Errorf("second detail is %v, want the revision", details[1])
+	}
+
+	st, _ = status.FromError(statusError("Error getting post", apierror.New(errors.New("missing"), apierror.StatusNotFound, apierror.MethodGRPC)))
+	if st.Code() != codes.NotFound || len(st.Details()) != 0 {
+		t.Errorf("got status %s with details %v, want NotFound without details", st.Code(), st.Details())
+	}
+}
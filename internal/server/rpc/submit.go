@@ -0,0 +1,19 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/motdpb"
+)
+
+func (r Rpc) SubmitPost(ctx context.Context, req *motdpb.SubmitPostRequest) (*motdpb.PostRevision, error) {
+	revision, apiErr := r.processor.SubmitForm(newPost(req.GetPost(), authorName(ctx)))
+	if apiErr != nil {
+		msg := "Error processing submit request: " + apiErr.Error()
+		log.Println(msg)
+		return nil, r.saveError(msg, apiErr, revision)
+	}
+	log.Println("Submitted post")
+	return r.postRevision(revision.ID)
+}
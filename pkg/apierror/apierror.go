@@ -1,16 +1,41 @@
 package apierror
 
 import (
+	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
+	"log"
 
 	"gopkg.in/go-playground/validator.v9"
 )
 
 type IApiError interface {
 	Error() string
-	Status() string
+	Status() Status
 	Code() int
+	Fields() []FieldError
+}
+
+// FieldError is a problem with one field of a request, such as the title of a
+// post, so that clients can point at the field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is implemented by errors caused by fields of a request. New
+// keeps the fields of an error that wraps one.
+type FieldErrors interface {
+	FieldErrors() []FieldError
+}
+
+// Envelope is the JSON body an error is sent to clients as.
+type Envelope struct {
+	Status    string       `json:"status"`
+	Code      int          `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields"`
+	RequestID string       `json:"request_id,omitempty"`
 }
 
 const (
@@ -22,24 +47,38 @@ type ApiError struct {
 	IApiError
 	validator *validator.Validate
 	err       error  `validate:"required"`
-	status    string `validate:"required"`
+	status    Status `validate:"required"`
 	method    string `validate:"required"`
+	fields    []FieldError
 }
 
-func New(e error, status string, m string) IApiError {
+// New returns an error with status, sent with the code of the status for the
+// method m. An error with a status that does not exist is a mistake, which is
+// reported as an INTERNAL error that still wraps e.
+func New(e error, status Status, m string) IApiError {
 	apiErr := &ApiError{
 		validator: validator.New(),
 		err:       e,
 		status:    status,
 		method:    m,
 	}
+	var fe FieldErrors
+	if errors.As(e, &fe) {
+		apiErr.fields = fe.FieldErrors()
+	}
 	if err := apiErr.validate(); err != nil {
-		msg := "error validating ApiError '" + status + "' choosing default INTERNAL error using HTTP method: " + err.Error()
+		msg := "error validating ApiError '" + string(status) + "' choosing default INTERNAL error using HTTP method: " + err.Error()
+		log.Println(msg)
+		cause := errors.New(msg)
+		if e != nil {
+			cause = fmt.Errorf("%s: %w", msg, e)
+		}
 		return &ApiError{
 			validator: validator.New(),
-			err:       errors.New(msg),
-			status:    "INTERNAL",
-			method:    "HTTP",
+			err:       cause,
+			status:    StatusInternal,
+			method:    MethodHTTP,
+			fields:    apiErr.fields,
 		}
 	}
 	return apiErr
@@ -53,46 +92,61 @@ func (ae ApiError) validate() error {
 	if err != nil {
 		return errors.New("could not validate ApiError: " + err.Error())
 	}
-	validStatus := ae.statusList()
-	if _, ok := validStatus[ae.status]; !ok {
-		return errors.New("status does not exist: " + ae.status)
+	if ae.method != MethodHTTP && ae.method != MethodGRPC {
+		return errors.New("method does not exist: " + ae.method)
 	}
-	if _, ok := validStatus[ae.status][ae.method]; !ok {
-		return errors.New("method does not exist for status '" + ae.status + "': " + ae.status)
+	if _, ok := statusCode(ae.status, ae.method); !ok {
+		return errors.New("status does not exist: " + string(ae.status))
 	}
 	return nil
 }
 
-func (ae ApiError) statusList() map[string]map[string]int {
-	return map[string]map[string]int{
-		"OK": map[string]int{
-			"HTTP": http.StatusOK,
-			"GRPC": 0,
-		},
-		"BAD_REQUEST": map[string]int{
-			"HTTP": http.StatusBadRequest,
-			"GRPC": 3,
-		},
-		"NOT_FOUND": map[string]int{
-			"HTTP": http.StatusNotFound,
-			"GRPC": 3,
-		},
-		"INTERNAL": map[string]int{
-			"HTTP": http.StatusInternalServerError,
-			"GRPC": 13,
-		},
-	}
-}
-
 func (ae ApiError) Error() string {
 	return ae.err.Error()
 }
 
-func (ae ApiError) Status() string {
+func (ae ApiError) Status() Status {
 	return ae.status
 }
 
 func (ae ApiError) Code() int {
-	validStatus := ae.statusList()
-	return validStatus[ae.status][ae.method]
+	code, _ := statusCode(ae.status, ae.method)
+	return code
+}
+
+// Unwrap returns the error the ApiError was created with, so that errors.Is
+// and errors.As look through it.
+func (ae ApiError) Unwrap() error {
+	return ae.err
+}
+
+// Is reports whether the ApiError has the status target, so that
+// errors.Is(err, StatusNotFound) finds it.
+func (ae ApiError) Is(target error) bool {
+	status, ok := target.(Status)
+	return ok && status == ae.status
+}
+
+func (ae ApiError) Fields() []FieldError {
+	return ae.fields
+}
+
+// NewEnvelope returns the envelope apiErr is sent to clients in, described by
+// msg, which is usually the error with some context added.
+func NewEnvelope(apiErr IApiError, msg string, request_id string) Envelope {
+	fields := apiErr.Fields()
+	if fields == nil {
+		fields = []FieldError{}
+	}
+	return Envelope{
+		Status:    string(apiErr.Status()),
+		Code:      apiErr.Code(),
+		Message:   msg,
+		Fields:    fields,
+		RequestID: request_id,
+	}
+}
+
+func (ae ApiError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewEnvelope(ae, ae.Error(), ""))
 }
@@ -0,0 +1,169 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatusCodes(t *testing.T) {
+	tests := []struct {
+		status apierror.Status
+		http   int
+		grpc   codes.Code
+	}{
+		{apierror.StatusOK, http.StatusOK, codes.OK},
+		{apierror.StatusBadRequest, http.StatusBadRequest, codes.InvalidArgument},
+		{apierror.StatusUnauthorized, http.StatusUnauthorized, codes.Unauthenticated},
+		{apierror.StatusForbidden, http.StatusForbidden, codes.PermissionDenied},
+		{apierror.StatusNotFound, http.StatusNotFound, codes.NotFound},
+		{apierror.StatusConflict, http.StatusConflict, codes.Aborted},
+		{apierror.StatusUnprocessable, http.StatusUnprocessableEntity, codes.InvalidArgument},
+		{apierror.StatusTooManyRequests, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{apierror.StatusInternal, http.StatusInternalServerError, codes.Internal},
+		{apierror.StatusUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			apiErr := apierror.New(errors.New("failed"), tt.status, apierror.MethodHTTP)
+			if apiErr.Status() != tt.status || apiErr.Code() != tt.http {
+				t.Errorf("HTTP error is %s %d, want %s %d", apiErr.Status(), apiErr.Code(), tt.status, tt.http)
+			}
+			apiErr = apierror.New(errors.New("failed"), tt.status, apierror.MethodGRPC)
+			if apiErr.Status() != tt.status || apiErr.Code() != int(tt.grpc) {
+				t.Errorf("gRPC error is %s %d, want %s %d", apiErr.Status(), apiErr.Code(), tt.status, tt.grpc)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// Statuses stay registered, so each run of the test registers a new one
+	status := apierror.Status("TEAPOT_" + strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err := apierror.Register(status, http.StatusTeapot, int(codes.FailedPrecondition)); err != nil {
+		t.Fatal(err)
+	}
+	apiErr := apierror.New(errors.New("teapot"), status, apierror.MethodHTTP)
+	if apiErr.Status() != status || apiErr.Code() != http.StatusTeapot {
+		t.Errorf("HTTP error is %s %d, want %s %d", apiErr.Status(), apiErr.Code(), status, http.StatusTeapot)
+	}
+	apiErr = apierror.New(errors.New("teapot"), status, apierror.MethodGRPC)
+	if apiErr.Code() != int(codes.FailedPrecondition) {
+		t.Errorf("gRPC code is %d, want %d", apiErr.Code(), codes.FailedPrecondition)
+	}
+	tests := []struct {
+		name   string
+		status apierror.Status
+	}{
+		{"registered", status},
+		{"built in", apierror.StatusNotFound},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := apierror.Register(tt.status, http.StatusOK, int(codes.OK)); err == nil {
+				t.Errorf("registered status %q", tt.status)
+			}
+		})
+	}
+	// The built in status keeps its codes
+	apiErr = apierror.New(errors.New("missing"), apierror.StatusNotFound, apierror.MethodHTTP)
+	if apiErr.Code() != http.StatusNotFound {
+		t.Errorf("NOT_FOUND is sent as %d after registering it again", apiErr.Code())
+	}
+}
+
+func TestUnknownStatus(t *testing.T) {
+	cause := errors.New("cause")
+	tests := []struct {
+		name   string
+		status apierror.Status
+		method string
+	}{
+		{"unknown status", apierror.Status("UNKNOWN"), apierror.MethodGRPC},
+		{"empty status", "", apierror.MethodHTTP},
+		{"unknown method", apierror.StatusNotFound, "SMTP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := apierror.New(fmt.Errorf("wrapped: %w", cause), tt.status, tt.method)
+			if apiErr.Status() != apierror.StatusInternal || apiErr.Code() != http.StatusInternalServerError {
+				t.Errorf("error is %s %d, want INTERNAL %d", apiErr.Status(), apiErr.Code(), http.StatusInternalServerError)
+			}
+			if !errors.Is(apiErr, cause) {
+				t.Errorf("%v does not wrap its cause", apiErr)
+			}
+			if !errors.Is(apiErr, apierror.StatusInternal) {
+				t.Errorf("%v is not INTERNAL", apiErr)
+			}
+		})
+	}
+}
+
+func TestErrorsIs(t *testing.T) {
+	cause := errors.New("cause")
+	apiErr := apierror.New(cause, apierror.StatusNotFound, apierror.MethodHTTP)
+	if !errors.Is(apiErr, apierror.StatusNotFound) {
+		t.Error("NOT_FOUND error is not StatusNotFound")
+	}
+	if errors.Is(apiErr, apierror.StatusInternal) {
+		t.Error("NOT_FOUND error is StatusInternal")
+	}
+	if !errors.Is(apiErr, cause) {
+		t.Error("error does not wrap its cause")
+	}
+	// The status is found through errors wrapping the ApiError
+	wrapped := fmt.Errorf("handler: %w", apiErr)
+	if !errors.Is(wrapped, apierror.StatusNotFound) {
+		t.Error("wrapped NOT_FOUND error is not StatusNotFound")
+	}
+	outer := apierror.New(wrapped, apierror.StatusInternal, apierror.MethodHTTP)
+	if !errors.Is(outer, apierror.StatusInternal) || !errors.Is(outer, apierror.StatusNotFound) {
+		t.Error("statuses of nested errors are not found")
+	}
+}
+
+func TestErrorsAsValidationError(t *testing.T) {
+	p := post.New(apierror.MethodHTTP, "admin")
+	p.Title = "Invalid/Title"
+	err := p.Validate()
+	if err == nil {
+		t.Fatal("invalid post was validated")
+	}
+	apiErr := apierror.New(fmt.Errorf("invalid post: %w", err), apierror.StatusBadRequest, apierror.MethodHTTP)
+	var validation *post.ValidationError
+	if !errors.As(apiErr, &validation) {
+		t.Fatalf("%v does not wrap a ValidationError", apiErr)
+	}
+	if !reflect.DeepEqual(apiErr.Fields(), validation.Fields) {
+		t.Errorf("error has fields %+v, want %+v", apiErr.Fields(), validation.Fields)
+	}
+	if len(apiErr.Fields()) == 0 {
+		t.Error("error has no fields")
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	apiErr := apierror.New(errors.New("cause"), apierror.StatusConflict, apierror.MethodHTTP)
+	data, err := json.Marshal(apiErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"status":"CONFLICT","code":409,"message":"cause","fields":[]}`
+	if string(data) != want {
+		t.Errorf("error is marshaled as %s, want %s", data, want)
+	}
+	envelope := apierror.NewEnvelope(apiErr, "saving: cause", "request-1")
+	if envelope.Message != "saving: cause" || envelope.RequestID != "request-1" || envelope.Code != http.StatusConflict {
+		t.Errorf("got envelope %+v", envelope)
+	}
+}
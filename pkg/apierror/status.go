@@ -0,0 +1,78 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Status is the kind of an ApiError, which decides the code it is sent with.
+// A Status is also an error, so that errors.Is can check the status of an
+// ApiError anywhere in a chain of errors.
+type Status string
+
+const (
+	StatusOK              Status = "OK"
+	StatusBadRequest      Status = "BAD_REQUEST"
+	StatusUnauthorized    Status = "UNAUTHORIZED"
+	StatusForbidden       Status = "FORBIDDEN"
+	StatusNotFound        Status = "NOT_FOUND"
+	StatusConflict        Status = "CONFLICT"
+	StatusUnprocessable   Status = "UNPROCESSABLE"
+	StatusTooManyRequests Status = "TOO_MANY_REQUESTS"
+	StatusInternal        Status = "INTERNAL"
+	StatusUnavailable     Status = "UNAVAILABLE"
+)
+
+func (s Status) Error() string {
+	return string(s)
+}
+
+// statusCodes are the codes a status is sent with by each method.
+type statusCodes map[string]int
+
+var (
+	statusMu sync.RWMutex
+	statuses = map[Status]statusCodes{
+		StatusOK:              {MethodHTTP: http.StatusOK, MethodGRPC: 0},
+		StatusBadRequest:      {MethodHTTP: http.StatusBadRequest, MethodGRPC: 3},
+		StatusUnauthorized:    {MethodHTTP: http.StatusUnauthorized, MethodGRPC: 16},
+		StatusForbidden:       {MethodHTTP: http.StatusForbidden, MethodGRPC: 7},
+		StatusNotFound:        {MethodHTTP: http.StatusNotFound, MethodGRPC: 5},
+		StatusConflict:        {MethodHTTP: http.StatusConflict, MethodGRPC: 10},
+		StatusUnprocessable:   {MethodHTTP: http.StatusUnprocessableEntity, MethodGRPC: 3},
+		StatusTooManyRequests: {MethodHTTP: http.StatusTooManyRequests, MethodGRPC: 8},
+		StatusInternal:        {MethodHTTP: http.StatusInternalServerError, MethodGRPC: 13},
+		StatusUnavailable:     {MethodHTTP: http.StatusServiceUnavailable, MethodGRPC: 14},
+	}
+)
+
+// Register adds status to the statuses errors can be created with, sent as
+// http_code over HTTP and grpc_code over gRPC. The built in statuses cannot
+// be changed.
+func Register(status Status, http_code int, grpc_code int) error {
+	if status == "" {
+		return errors.New("cannot register an empty status")
+	}
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if _, exists := statuses[status]; exists {
+		msg := "status " + string(status) + " is already registered"
+		return errors.New(msg)
+	}
+	statuses[status] = statusCodes{MethodHTTP: http_code, MethodGRPC: grpc_code}
+	return nil
+}
+
+// statusCode returns the code status is sent with by method, and whether
+// there is one.
+func statusCode(status Status, method string) (int, bool) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	codes, ok := statuses[status]
+	if !ok {
+		return 0, false
+	}
+	code, ok := codes[method]
+	return code, ok
+}
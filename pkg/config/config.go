@@ -2,12 +2,16 @@ package config
 
 import (
 	"gitlab.com/joshraphael/motdoftheday/internal/server/rest"
+	"gitlab.com/joshraphael/motdoftheday/internal/server/rpc"
 	"gitlab.com/joshraphael/motdoftheday/pkg/database"
 	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+	"gitlab.com/joshraphael/motdoftheday/pkg/scheduler"
 )
 
 type Config struct {
 	Rest       rest.Config       `yaml:"rest" validate:"required"`
+	Grpc       rpc.Config        `yaml:"grpc"`
 	Database   database.Config   `yaml:"db" validate:"required"`
 	Processors processors.Config `yaml:"processors" validate:"required"`
+	Scheduler  scheduler.Config  `yaml:"scheduler"`
 }
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ApiToken struct {
+	ID         int64  `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	TokenHash  string `db:"token_hash" json:"-"`
+	UserID     int64  `db:"user_id" json:"user_id"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
+}
+
+func (database *Database) CreateApiToken(db_user *User, name string, token_hash string) (*ApiToken, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for CreateApiToken: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	api_token_id, err := database.insertApiToken(tx, db_user.ID, name, token_hash)
+	if err != nil {
+		msg := "cannot insert api token in CreateApiToken: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateApiToken: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	t, err := database.getApiTokenById(tx, *api_token_id)
+	if err != nil {
+		msg := "cannot get api token in CreateApiToken: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateApiToken: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in CreateApiToken: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateApiToken: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return t, nil
+}
+
+func (database *Database) GetApiTokenById(api_token_id int64) (*ApiToken, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetApiTokenById: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	t, err := database.getApiTokenById(tx, api_token_id)
+	if err != nil {
+		msg := "cannot get api token in GetApiTokenById: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetApiTokenById: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetApiTokenById: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetApiTokenById: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return t, nil
+}
+
+func (database *Database) GetApiTokenByTokenHash(token_hash string) (*ApiToken, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetApiTokenByTokenHash: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	t, err := database.getApiTokenByTokenHash(tx, token_hash)
+	if err != nil {
+		msg := "cannot get api token in GetApiTokenByTokenHash: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetApiTokenByTokenHash: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetApiTokenByTokenHash: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetApiTokenByTokenHash: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return t, nil
+}
+
+func (database *Database) GetUserApiTokens(db_user *User) ([]ApiToken, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetUserApiTokens: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	ts, err := database.getUserApiTokens(tx, db_user.ID)
+	if err != nil {
+		msg := "cannot get api tokens in GetUserApiTokens: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetUserApiTokens: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetUserApiTokens: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetUserApiTokens: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return ts, nil
+}
+
+func (database *Database) DeleteApiToken(api_token *ApiToken) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for DeleteApiToken: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.deleteApiToken(tx, api_token.ID)
+	if err != nil {
+		msg := "cannot delete api token in DeleteApiToken: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteApiToken: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in DeleteApiToken: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteApiToken: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) getApiTokenById(tx *sqlx.Tx, api_token_id int64) (*ApiToken, error) {
+	cols := `id, name, token_hash, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM api_token WHERE id = $1`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getApiTokenById: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(api_token_id)
+	var t ApiToken
+	err = row.StructScan(&t)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal api token from getApiTokenById: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &t, nil
+}
+
+func (database *Database) getApiTokenByTokenHash(tx *sqlx.Tx, token_hash string) (*ApiToken, error) {
+	cols := `id, name, token_hash, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM api_token WHERE token_hash = $1`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getApiTokenByTokenHash: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(token_hash)
+	var t ApiToken
+	err = row.StructScan(&t)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal api token from getApiTokenByTokenHash: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &t, nil
+}
+
+func (database *Database) getUserApiTokens(tx *sqlx.Tx, user_id int64) ([]ApiToken, error) {
+	cols := `id, name, token_hash, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM api_token WHERE user_id = $1 ORDER BY id ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getUserApiTokens: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx(user_id)
+	if err != nil {
+		msg := "cannot execute statement for getUserApiTokens: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	ts := []ApiToken{}
+	for rows.Next() {
+		var t ApiToken
+		err = rows.StructScan(&t)
+		if err != nil {
+			msg := "cannot unmarshal api token from getUserApiTokens: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func (database *Database) insertApiToken(tx *sqlx.Tx, user_id int64, name string, token_hash string) (*int64, error) {
+	cols := `name, token_hash, user_id`
+	query := fmt.Sprintf(`INSERT INTO api_token (%s) VALUES($1, $2, $3)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for insertApiToken: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(name, token_hash, user_id)
+	if err != nil {
+		msg := "cannot execute query in insertApiToken: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in insertApiToken: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in insertApiToken but " + strconv.FormatInt(rows, 10) + " rows were"
+		return nil, errors.New(msg)
+	}
+	api_token_id, err := res.LastInsertId()
+	if err != nil {
+		msg := "cannot get last insert id in insertApiToken: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return &api_token_id, nil
+}
+
+func (database *Database) deleteApiToken(tx *sqlx.Tx, api_token_id int64) error {
+	query := `DELETE FROM api_token WHERE id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteApiToken: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(api_token_id)
+	if err != nil {
+		msg := "cannot execute query in deleteApiToken: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) deleteUserApiTokens(tx *sqlx.Tx, user_id int64) error {
+	query := `DELETE FROM api_token WHERE user_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteUserApiTokens: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(user_id)
+	if err != nil {
+		msg := "cannot execute query in deleteUserApiTokens: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
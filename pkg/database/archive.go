@@ -0,0 +1,414 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ArchiveVersion is the version of the archive layout written by
+// ExportArchive. ImportArchive only reads archives of the same version.
+const ArchiveVersion int64 = 1
+
+// Archive is a copy of every user, post, revision, tag, category and asset in
+// the database. Rows keep their ids so that links between them stay valid.
+type Archive struct {
+	Version       int64          `json:"version"`
+	SchemaVersion int64          `json:"schema_version"`
+	Users         []ArchiveUser  `json:"users"`
+	Tags          []Tag          `json:"tags"`
+	Categories    []Category     `json:"categories"`
+	Posts         []ArchivePost  `json:"posts"`
+	Assets        []ArchiveAsset `json:"assets"`
+}
+
+// ArchiveUser is a user without its password hash, so that an archive never
+// holds credentials. Imported users have to be given a new password.
+type ArchiveUser struct {
+	ID         int64  `json:"id"`
+	Username   string `json:"user_name"`
+	Firstname  string `json:"first_name"`
+	Lastname   string `json:"last_name"`
+	Admin      int64  `json:"admin"`
+	UpdateTime int64  `json:"update_time"`
+	InsertTime int64  `json:"insert_time"`
+}
+
+// ArchiveAsset is an asset along with its data, which Asset leaves out of
+// JSON.
+type ArchiveAsset struct {
+	Asset
+	Data []byte `json:"data"`
+}
+
+type ArchivePost struct {
+	Post
+	History []ArchiveRevision `json:"history"`
+}
+
+type ArchiveRevision struct {
+	PostHistory
+	TagIDs      []int64 `json:"tag_ids"`
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+// ExportArchive reads the whole database in a single transaction so that the
+// archive is consistent.
+func (database *Database) ExportArchive() (*Archive, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for ExportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	archive, err := database.exportArchive(tx)
+	if err != nil {
+		msg := "cannot export archive in ExportArchive: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ExportArchive: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in ExportArchive: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ExportArchive: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return archive, nil
+}
+
+// ImportArchive restores an archive into a database that has no posts, tags
+// or categories yet. Users are matched by user name, so that the admin user
+// created by the migrations takes the names of the archived one and keeps its
+// password. Other users are created without a password.
+func (database *Database) ImportArchive(archive *Archive) error {
+	if archive.Version != ArchiveVersion {
+		msg := "cannot import archive version " + strconv.FormatInt(archive.Version, 10) + ", expected version " + strconv.FormatInt(ArchiveVersion, 10)
+		return errors.New(msg)
+	}
+	err := validateArchive(archive)
+	if err != nil {
+		msg := "invalid archive in ImportArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for ImportArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.importArchive(tx, archive)
+	if err != nil {
+		msg := "cannot import archive in ImportArchive: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ImportArchive: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in ImportArchive: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ImportArchive: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) exportArchive(tx *sqlx.Tx) (*Archive, error) {
+	var schema_version int64
+	err := tx.Get(&schema_version, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)
+	if err != nil {
+		msg := "cannot get schema version in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	users, err := database.getUsers(tx)
+	if err != nil {
+		msg := "cannot get users in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	tags, err := database.getTags(tx)
+	if err != nil {
+		msg := "cannot get tags in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	categories, err := database.getCategories(tx)
+	if err != nil {
+		msg := "cannot get categories in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	posts, err := database.getPosts(tx)
+	if err != nil {
+		msg := "cannot get posts in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	post_tags := []PostTag{}
+	err = tx.Select(&post_tags, `SELECT id, post_history_id, tag_id, insert_time FROM post_tags ORDER BY id ASC`)
+	if err != nil {
+		msg := "cannot get post tags in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	history_tags := make(map[int64][]int64)
+	for i := range post_tags {
+		history_tags[post_tags[i].PostID] = append(history_tags[post_tags[i].PostID], post_tags[i].TagID)
+	}
+	post_categories := []PostCategory{}
+	err = tx.Select(&post_categories, `SELECT id, post_history_id, category_id, insert_time FROM post_categories ORDER BY id ASC`)
+	if err != nil {
+		msg := "cannot get post categories in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	history_categories := make(map[int64][]int64)
+	for i := range post_categories {
+		history_categories[post_categories[i].PostID] = append(history_categories[post_categories[i].PostID], post_categories[i].CategoryID)
+	}
+	assets, err := database.getAssets(tx)
+	if err != nil {
+		msg := "cannot get assets in exportArchive: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	archive := &Archive{
+		Version:       ArchiveVersion,
+		SchemaVersion: schema_version,
+		Users:         []ArchiveUser{},
+		Tags:          tags,
+		Categories:    categories,
+		Posts:         []ArchivePost{},
+		Assets:        []ArchiveAsset{},
+	}
+	for i := range assets {
+		archive.Assets = append(archive.Assets, ArchiveAsset{
+			Asset: assets[i],
+			Data:  assets[i].Data,
+		})
+	}
+	for i := range users {
+		archive.Users = append(archive.Users, ArchiveUser{
+			ID:         users[i].ID,
+			Username:   users[i].Username,
+			Firstname:  users[i].Firstname,
+			Lastname:   users[i].Lastname,
+			Admin:      users[i].Admin,
+			UpdateTime: users[i].UpdateTime,
+			InsertTime: users[i].InsertTime,
+		})
+	}
+	for i := range posts {
+		history, err := database.getPostHistory(tx, &posts[i])
+		if err != nil {
+			msg := "cannot get post history in exportArchive: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ap := ArchivePost{
+			Post:    posts[i],
+			History: []ArchiveRevision{},
+		}
+		for j := range history {
+			revision := ArchiveRevision{
+				PostHistory: history[j],
+				TagIDs:      history_tags[history[j].ID],
+				CategoryIDs: history_categories[history[j].ID],
+			}
+			if revision.TagIDs == nil {
+				revision.TagIDs = []int64{}
+			}
+			if revision.CategoryIDs == nil {
+				revision.CategoryIDs = []int64{}
+			}
+			ap.History = append(ap.History, revision)
+		}
+		archive.Posts = append(archive.Posts, ap)
+	}
+	return archive, nil
+}
+
+// validateArchive rejects archives written by a newer schema than this
+// binary knows, and published names that would let publishers write outside
+// their directory.
+func validateArchive(archive *Archive) error {
+	migrations, err := Migrations()
+	if err != nil {
+		msg := "cannot get migrations in validateArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	var latest int64
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if archive.SchemaVersion < 1 || archive.SchemaVersion > latest {
+		msg := "unknown schema version " + strconv.FormatInt(archive.SchemaVersion, 10) + ", expected a version between 1 and " + strconv.FormatInt(latest, 10)
+		return errors.New(msg)
+	}
+	for i := range archive.Posts {
+		name := archive.Posts[i].PublishedName
+		if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+			msg := "invalid published name '" + name + "' for post '" + archive.Posts[i].UrlTitle + "'"
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+func (database *Database) importArchive(tx *sqlx.Tx, archive *Archive) error {
+	for _, table := range []string{"post", "tag", "category", "asset"} {
+		var count int64
+		err := tx.Get(&count, `SELECT COUNT(*) FROM `+table)
+		if err != nil {
+			msg := "cannot count " + table + " rows in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+		if count != 0 {
+			msg := "database already has " + table + " rows, archives can only be imported into an empty database"
+			return errors.New(msg)
+		}
+	}
+	user_ids := make(map[int64]int64)
+	for i := range archive.Users {
+		user_id, err := database.importArchiveUser(tx, archive.Users[i])
+		if err != nil {
+			msg := "cannot import user '" + archive.Users[i].Username + "' in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+		user_ids[archive.Users[i].ID] = *user_id
+	}
+	for i := range archive.Tags {
+		t := archive.Tags[i]
+		query := `INSERT INTO tag (id, name, user_id, insert_time) VALUES($1, $2, $3, $4)`
+		err := database.execArchive(tx, query, t.ID, t.Name, user_ids[t.UserID], t.InsertTime)
+		if err != nil {
+			msg := "cannot import tag '" + t.Name + "' in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	for i := range archive.Categories {
+		c := archive.Categories[i]
+		query := `INSERT INTO category (id, name, user_id, insert_time) VALUES($1, $2, $3, $4)`
+		err := database.execArchive(tx, query, c.ID, c.Name, user_ids[c.UserID], c.InsertTime)
+		if err != nil {
+			msg := "cannot import category '" + c.Name + "' in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	for i := range archive.Posts {
+		err := database.importArchivePost(tx, archive.Posts[i], user_ids)
+		if err != nil {
+			msg := "cannot import post '" + archive.Posts[i].UrlTitle + "' in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	for i := range archive.Assets {
+		a := archive.Assets[i]
+		cols := `id, hash, name, content_type, size, data, user_id, insert_time`
+		query := fmt.Sprintf(`INSERT INTO asset (%s) VALUES($1, $2, $3, $4, $5, $6, $7, $8)`, cols)
+		err := database.execArchive(tx, query, a.ID, a.Hash, a.Name, a.ContentType, int64(len(a.Data)), a.Data, user_ids[a.UserID], a.InsertTime)
+		if err != nil {
+			msg := "cannot import asset '" + a.Name + "' in importArchive: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+func (database *Database) importArchiveUser(tx *sqlx.Tx, u ArchiveUser) (*int64, error) {
+	db_user, err := database.getUserByUsername(tx, u.Username)
+	if err != nil {
+		msg := "cannot get user in importArchiveUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if db_user != nil {
+		query := `UPDATE user SET first_name = $1, last_name = $2, admin = $3, update_time = $4, insert_time = $5 WHERE id = $6`
+		err = database.execArchive(tx, query, u.Firstname, u.Lastname, u.Admin, u.UpdateTime, u.InsertTime, db_user.ID)
+		if err != nil {
+			msg := "cannot update user in importArchiveUser: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		return &db_user.ID, nil
+	}
+	cols := `user_name, first_name, last_name, admin, update_time, insert_time`
+	query := fmt.Sprintf(`INSERT INTO user (%s) VALUES(LOWER($1), $2, $3, $4, $5, $6)`, cols)
+	res, err := tx.Exec(query, u.Username, u.Firstname, u.Lastname, u.Admin, u.UpdateTime, u.InsertTime)
+	if err != nil {
+		msg := "cannot insert user in importArchiveUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	user_id, err := res.LastInsertId()
+	if err != nil {
+		msg := "cannot get last insert id in importArchiveUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return &user_id, nil
+}
+
+func (database *Database) importArchivePost(tx *sqlx.Tx, p ArchivePost, user_ids map[int64]int64) error {
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
+	query := fmt.Sprintf(`INSERT INTO post (%s) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`, cols)
+	err := database.execArchive(tx, query, p.ID, p.UrlTitle, user_ids[p.UserID], p.Title, p.Posted, p.Format, p.PublishAt, p.PublishedName, p.UpdateTime, p.InsertTime)
+	if err != nil {
+		msg := "cannot insert post in importArchivePost: " + err.Error()
+		return errors.New(msg)
+	}
+	for i := range p.History {
+		h := p.History[i]
+		cols := `id, post_id, body, method, insert_time`
+		query := fmt.Sprintf(`INSERT INTO post_history (%s) VALUES($1, $2, $3, $4, $5)`, cols)
+		err = database.execArchive(tx, query, h.ID, p.ID, h.Body, h.Method, h.InsertTime)
+		if err != nil {
+			msg := "cannot insert post history in importArchivePost: " + err.Error()
+			return errors.New(msg)
+		}
+		_, err = database.insertPostTags(tx, h.ID, h.TagIDs)
+		if err != nil {
+			msg := "cannot insert post tags in importArchivePost: " + err.Error()
+			return errors.New(msg)
+		}
+		_, err = database.insertPostCategories(tx, h.ID, h.CategoryIDs)
+		if err != nil {
+			msg := "cannot insert post categories in importArchivePost: " + err.Error()
+			return errors.New(msg)
+		}
+		err = database.insertPostSearch(tx, h.ID, p.ID, p.Title, h.Body)
+		if err != nil {
+			msg := "cannot index post history in importArchivePost: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// execArchive runs a statement that must change exactly one row.
+func (database *Database) execArchive(tx *sqlx.Tx, query string, args ...interface{}) error {
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for execArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(args...)
+	if err != nil {
+		msg := "cannot execute query in execArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in execArchive: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in execArchive but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Asset struct {
+	ID          int64  `db:"id" json:"id"`
+	Hash        string `db:"hash" json:"hash"`
+	Name        string `db:"name" json:"name"`
+	ContentType string `db:"content_type" json:"content_type"`
+	Size        int64  `db:"size" json:"size"`
+	Data        []byte `db:"data" json:"-"`
+	UserID      int64  `db:"user_id" json:"user_id"`
+	InsertTime  int64  `db:"insert_time" json:"insert_time"`
+}
+
+// CreateAsset stores data uploaded by db_user, returning the asset that
+// already holds data when there is one.
+func (database *Database) CreateAsset(db_user *User, hash string, name string, content_type string, data []byte) (*Asset, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for CreateAsset: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	a, err := database.getAssetByHash(tx, hash)
+	if err != nil {
+		msg := "cannot get asset in CreateAsset: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateAsset: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if a == nil {
+		asset_id, err := database.insertAsset(tx, db_user.ID, hash, name, content_type, data)
+		if err != nil {
+			msg := "cannot insert asset in CreateAsset: " + err.Error()
+			err = tx.Rollback()
+			if err != nil {
+				fatal := "cannot rollback in CreateAsset: " + msg + ": " + err.Error()
+				return nil, errors.New(fatal)
+			}
+			return nil, errors.New(msg)
+		}
+		a, err = database.getAssetById(tx, *asset_id)
+		if err != nil {
+			msg := "cannot get asset in CreateAsset: " + err.Error()
+			err = tx.Rollback()
+			if err != nil {
+				fatal := "cannot rollback in CreateAsset: " + msg + ": " + err.Error()
+				return nil, errors.New(fatal)
+			}
+			return nil, errors.New(msg)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in CreateAsset: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateAsset: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return a, nil
+}
+
+func (database *Database) GetAssetById(asset_id int64) (*Asset, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetAssetById: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	a, err := database.getAssetById(tx, asset_id)
+	if err != nil {
+		msg := "cannot get asset in GetAssetById: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetAssetById: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetAssetById: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetAssetById: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return a, nil
+}
+
+func (database *Database) getAssetById(tx *sqlx.Tx, asset_id int64) (*Asset, error) {
+	cols := `id, hash, name, content_type, size, data, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM asset WHERE id = $1`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getAssetById: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(asset_id)
+	var a Asset
+	err = row.StructScan(&a)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal asset from getAssetById: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &a, nil
+}
+
+func (database *Database) getAssetByHash(tx *sqlx.Tx, hash string) (*Asset, error) {
+	cols := `id, hash, name, content_type, size, data, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM asset WHERE hash = $1`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getAssetByHash: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(hash)
+	var a Asset
+	err = row.StructScan(&a)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal asset from getAssetByHash: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &a, nil
+}
+
+func (database *Database) getAssets(tx *sqlx.Tx) ([]Asset, error) {
+	cols := `id, hash, name, content_type, size, data, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM asset ORDER BY id ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getAssets: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		msg := "cannot execute statement for getAssets: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	as := []Asset{}
+	for rows.Next() {
+		var a Asset
+		err = rows.StructScan(&a)
+		if err != nil {
+			msg := "cannot unmarshal asset from getAssets: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		as = append(as, a)
+	}
+	return as, nil
+}
+
+func (database *Database) insertAsset(tx *sqlx.Tx, user_id int64, hash string, name string, content_type string, data []byte) (*int64, error) {
+	cols := `hash, name, content_type, size, data, user_id`
+	query := fmt.Sprintf(`INSERT INTO asset (%s) VALUES($1, $2, $3, $4, $5, $6)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for insertAsset: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(hash, name, content_type, int64(len(data)), data, user_id)
+	if err != nil {
+		msg := "cannot execute query in insertAsset: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in insertAsset: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in insertAsset but " + strconv.FormatInt(rows, 10) + " rows were"
+		return nil, errors.New(msg)
+	}
+	asset_id, err := res.LastInsertId()
+	if err != nil {
+		msg := "cannot get last insert id in insertAsset: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return &asset_id, nil
+}
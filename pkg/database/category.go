@@ -4,16 +4,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
 type Category struct {
-	ID         int64  `db:"id"`
-	Name       string `db:"name"`
-	UserID     int64  `db:"user_id"`
-	InsertTime int64  `db:"insert_time"`
+	ID         int64  `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	UserID     int64  `db:"user_id" json:"user_id"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
 }
 
 func (database *Database) GetCategoryById(category_id int64) (*Category, error) {
@@ -132,7 +133,7 @@ func (database *Database) getCategoryByID(tx *sqlx.Tx, category_id int64) (*Cate
 	return &c, nil
 }
 
-func (database *Database) insertCategories(tx *sqlx.Tx, post post.Post) ([]int64, error) {
+func (database *Database) insertCategories(tx *sqlx.Tx, post post.Post, user_id int64) ([]int64, error) {
 	url_categories := post.UrlCategories()
 	category_ids := []int64{}
 	for i := range url_categories {
@@ -145,7 +146,7 @@ func (database *Database) insertCategories(tx *sqlx.Tx, post post.Post) ([]int64
 		if category != nil {
 			category_ids = append(category_ids, category.ID)
 		} else {
-			category_id, err := database.insertCategory(tx, name)
+			category_id, err := database.insertCategory(tx, name, user_id)
 			if err != nil {
 				msg := "cannot insert category for insertCategories: " + err.Error()
 				return nil, errors.New(msg)
@@ -156,16 +157,16 @@ func (database *Database) insertCategories(tx *sqlx.Tx, post post.Post) ([]int64
 	return category_ids, nil
 }
 
-func (database *Database) insertCategory(tx *sqlx.Tx, name string) (*int64, error) {
+func (database *Database) insertCategory(tx *sqlx.Tx, name string, user_id int64) (*int64, error) {
 	cols := `user_id, name`
-	query := fmt.Sprintf(`INSERT INTO category (%s) VALUES(1, $1)`, cols)
+	query := fmt.Sprintf(`INSERT INTO category (%s) VALUES($1, $2)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for insertCategory: " + err.Error()
 		return nil, errors.New(msg)
 	}
 	defer stmt.Close()
-	res, err := stmt.Exec(name)
+	res, err := stmt.Exec(user_id, name)
 	if err != nil {
 		msg := "cannot execute query in insertCategory: " + err.Error()
 		return nil, errors.New(msg)
@@ -176,7 +177,7 @@ func (database *Database) insertCategory(tx *sqlx.Tx, name string) (*int64, erro
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertCategory but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertCategory but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	category_id, err := res.LastInsertId()
@@ -186,3 +187,60 @@ func (database *Database) insertCategory(tx *sqlx.Tx, name string) (*int64, erro
 	}
 	return &category_id, nil
 }
+
+func (database *Database) GetCategories() ([]Category, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetCategories: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	cs, err := database.getCategories(tx)
+	if err != nil {
+		msg := "cannot get categories in GetCategories: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetCategories: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetCategories: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetCategories: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return cs, nil
+}
+
+func (database *Database) getCategories(tx *sqlx.Tx) ([]Category, error) {
+	cols := `id, name, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM category ORDER BY name ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getCategories: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		msg := "cannot execute statement for getCategories: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	cs := []Category{}
+	for rows.Next() {
+		var c Category
+		err = rows.StructScan(&c)
+		if err != nil {
+			msg := "cannot unmarshal category from getCategories: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
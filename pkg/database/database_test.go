@@ -0,0 +1,81 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// testFile returns a database file in a temporary directory, relative to the
+// working directory since database files are opened relative to it.
+func testFile(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := filepath.Rel(wd, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// openTestDatabase returns an empty database without any migration applied.
+func openTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db, sqlxDB, err := Open(Config{File: testFile(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	return db
+}
+
+// newTestDatabase returns a database migrated to the latest schema, which
+// only has the admin user.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db, sqlxDB, err := New(Config{File: testFile(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	return db
+}
+
+// testPost returns a post by the admin user with one tag and one category.
+func testPost(title string, body string) post.Post {
+	p := post.New(apierror.MethodHTTP, "admin")
+	p.Title = title
+	p.Tags = []string{"test"}
+	p.Categories = []string{"testing"}
+	p.Body = body
+	return p
+}
+
+// createTestPost stores p as a draft and returns it.
+func createTestPost(t *testing.T, db *Database, p post.Post) *Post {
+	t.Helper()
+	if _, err := db.CreatePost(p, DB_FALSE()); err != nil {
+		t.Fatal(err)
+	}
+	db_post, err := db.GetPostByUrlTitle(p.UrlTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db_post == nil {
+		t.Fatalf("post %s was not created", p.UrlTitle())
+	}
+	return db_post
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"errors"
 	"log"
-	"os"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -14,25 +13,42 @@ type Database struct {
 }
 
 type CompletePost struct {
-	Post       *Post
-	History    []PostHistory
-	Categories map[int64][]Category
-	Tags       map[int64][]Tag
+	Post       *Post                `json:"post"`
+	History    []PostHistory        `json:"history"`
+	Categories map[int64][]Category `json:"categories"`
+	Tags       map[int64][]Tag      `json:"tags"`
 }
 
 type CompletePostHistory struct {
-	Post       *Post
-	History    *PostHistory
-	Categories []Category
-	Tags       []Tag
+	Post       *Post        `json:"post"`
+	History    *PostHistory `json:"history"`
+	Categories []Category   `json:"categories"`
+	Tags       []Tag        `json:"tags"`
 }
 
+// New opens the database and migrates it to the latest schema.
 func New(c Config) (*Database, *sqlx.DB, error) {
+	db, database, err := Open(c)
+	if err != nil {
+		return nil, nil, err
+	}
 	db_name := "./" + c.File
-	if _, err := os.Stat(db_name); err != nil {
-		msg := "Database " + db_name + " does not exist: " + err.Error()
-		log.Fatalln(msg)
+	err = db.Migrate()
+	if err != nil {
+		msg := "cannot migrate database " + db_name + ": " + err.Error()
+		return nil, nil, errors.New(msg)
+	}
+	err = db.SyncSearchIndex()
+	if err != nil {
+		msg := "cannot index database " + db_name + ": " + err.Error()
+		return nil, nil, errors.New(msg)
 	}
+	return db, database, nil
+}
+
+// Open opens the database without changing its schema.
+func Open(c Config) (*Database, *sqlx.DB, error) {
+	db_name := "./" + c.File
 	database, err := sqlx.Open("sqlite3", db_name+"?_foreign_keys=on")
 	if err != nil {
 		log.Fatalln(err)
@@ -42,8 +58,14 @@ func New(c Config) (*Database, *sqlx.DB, error) {
 		msg := "bad ping: " + err.Error()
 		return nil, nil, errors.New(msg)
 	}
-	return &Database{
+	db := &Database{
 		db:  database,
 		cfg: c,
-	}, database, nil
+	}
+	err = db.checkSearchSupport()
+	if err != nil {
+		msg := "cannot use database " + db_name + ": " + err.Error()
+		return nil, nil, errors.New(msg)
+	}
+	return db, database, nil
 }
@@ -0,0 +1,337 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration files are named <version>_<name>.<up|down>.sql, for example
+// 0001_create_tables.up.sql, and are applied in ascending version order.
+var migrationName = regexp.MustCompile(`^([0-9]+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type SchemaMigration struct {
+	Version    int64  `db:"version" json:"version"`
+	Name       string `db:"name" json:"name"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
+}
+
+func Migrations() ([]Migration, error) {
+	files, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		msg := "cannot read embedded migrations: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	migrations := make(map[int64]*Migration)
+	for i := range files {
+		matches := migrationName.FindStringSubmatch(files[i].Name())
+		if matches == nil {
+			msg := "invalid migration file name: " + files[i].Name()
+			return nil, errors.New(msg)
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			msg := "invalid migration version in " + files[i].Name() + ": " + err.Error()
+			return nil, errors.New(msg)
+		}
+		data, err := migrationFiles.ReadFile(path.Join("migrations", files[i].Name()))
+		if err != nil {
+			msg := "cannot read migration " + files[i].Name() + ": " + err.Error()
+			return nil, errors.New(msg)
+		}
+		m, exists := migrations[version]
+		if !exists {
+			m = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			migrations[version] = m
+		}
+		if m.Name != matches[2] {
+			msg := "migration version " + matches[1] + " has conflicting names '" + m.Name + "' and '" + matches[2] + "'"
+			return nil, errors.New(msg)
+		}
+		switch matches[3] {
+		case "up":
+			m.Up = string(data)
+		case "down":
+			m.Down = string(data)
+		}
+	}
+	ms := []Migration{}
+	for _, m := range migrations {
+		if m.Up == "" || m.Down == "" {
+			msg := "migration " + strconv.FormatInt(m.Version, 10) + "_" + m.Name + " must have both an up and a down file"
+			return nil, errors.New(msg)
+		}
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Version < ms[j].Version
+	})
+	return ms, nil
+}
+
+// Migrate applies every embedded migration that has not yet been recorded in
+// schema_migrations.
+func (database *Database) Migrate() error {
+	migrations, err := Migrations()
+	if err != nil {
+		msg := "cannot load migrations in Migrate: " + err.Error()
+		return errors.New(msg)
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return database.MigrateTo(migrations[len(migrations)-1].Version)
+}
+
+// MigrateTo moves the schema up or down until version is the latest applied
+// migration. A version of 0 rolls back every migration.
+func (database *Database) MigrateTo(version int64) error {
+	migrations, err := Migrations()
+	if err != nil {
+		msg := "cannot load migrations in MigrateTo: " + err.Error()
+		return errors.New(msg)
+	}
+	if err := database.createSchemaMigrations(); err != nil {
+		msg := "cannot create schema_migrations in MigrateTo: " + err.Error()
+		return errors.New(msg)
+	}
+	applied, err := database.GetSchemaMigrations()
+	if err != nil {
+		msg := "cannot get applied migrations in MigrateTo: " + err.Error()
+		return errors.New(msg)
+	}
+	done := make(map[int64]bool)
+	for i := range applied {
+		done[applied[i].Version] = true
+	}
+	for i := range migrations {
+		if migrations[i].Version <= version && !done[migrations[i].Version] {
+			if err := database.applyMigration(migrations[i], true); err != nil {
+				msg := "cannot apply migration in MigrateTo: " + err.Error()
+				return errors.New(msg)
+			}
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version > version && done[migrations[i].Version] {
+			if err := database.applyMigration(migrations[i], false); err != nil {
+				msg := "cannot revert migration in MigrateTo: " + err.Error()
+				return errors.New(msg)
+			}
+		}
+	}
+	return nil
+}
+
+func (database *Database) GetSchemaVersion() (int64, error) {
+	applied, err := database.GetSchemaMigrations()
+	if err != nil {
+		msg := "cannot get applied migrations in GetSchemaVersion: " + err.Error()
+		return 0, errors.New(msg)
+	}
+	if len(applied) == 0 {
+		return 0, nil
+	}
+	return applied[len(applied)-1].Version, nil
+}
+
+func (database *Database) GetSchemaMigrations() ([]SchemaMigration, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetSchemaMigrations: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	sms, err := database.getSchemaMigrations(tx)
+	if err != nil {
+		msg := "cannot get schema migrations in GetSchemaMigrations: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetSchemaMigrations: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetSchemaMigrations: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetSchemaMigrations: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return sms, nil
+}
+
+func (database *Database) createSchemaMigrations() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+	    version     INTEGER NOT NULL CHECK(TYPEOF(version) = 'integer')     PRIMARY KEY,
+	    name        TEXT    NOT NULL CHECK(TYPEOF(name) = 'text'),
+	    insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer))
+	)`
+	_, err := database.db.Exec(query)
+	if err != nil {
+		msg := "cannot execute query in createSchemaMigrations: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// A migration containing this marker runs with foreign key enforcement turned
+// off, which SQLite requires when rebuilding a table that other tables
+// reference. Foreign keys are verified with foreign_key_check before commit.
+const foreignKeysOffMarker string = "-- motdoftheday: foreign_keys=off"
+
+func (database *Database) applyMigration(m Migration, up bool) error {
+	name := strconv.FormatInt(m.Version, 10) + "_" + m.Name
+	query := m.Up
+	if !up {
+		query = m.Down
+	}
+	ctx := context.Background()
+	conn, err := database.db.DB.Conn(ctx)
+	if err != nil {
+		msg := "cannot get connection for migration " + name + ": " + err.Error()
+		return errors.New(msg)
+	}
+	defer conn.Close()
+	foreignKeysOff := strings.Contains(query, foreignKeysOffMarker)
+	if foreignKeysOff {
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+			msg := "cannot disable foreign keys for migration " + name + ": " + err.Error()
+			return errors.New(msg)
+		}
+		defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		msg := "cannot begin transaction for migration " + name + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if up {
+		err = database.migrateUp(tx, m)
+	} else {
+		err = database.migrateDown(tx, m)
+	}
+	if err == nil && foreignKeysOff {
+		err = database.checkForeignKeys(tx)
+	}
+	if err != nil {
+		msg := "cannot run migration " + name + ": " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback migration " + name + ": " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit migration " + name + ": " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback migration " + name + ": " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) migrateUp(tx *sql.Tx, m Migration) error {
+	if _, err := tx.Exec(m.Up); err != nil {
+		msg := "cannot execute up migration in migrateUp: " + err.Error()
+		return errors.New(msg)
+	}
+	cols := `version, name`
+	query := fmt.Sprintf(`INSERT INTO schema_migrations (%s) VALUES($1, $2)`, cols)
+	if _, err := tx.Exec(query, m.Version, m.Name); err != nil {
+		msg := "cannot record migration in migrateUp: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) migrateDown(tx *sql.Tx, m Migration) error {
+	if _, err := tx.Exec(m.Down); err != nil {
+		msg := "cannot execute down migration in migrateDown: " + err.Error()
+		return errors.New(msg)
+	}
+	query := `DELETE FROM schema_migrations WHERE version = $1`
+	if _, err := tx.Exec(query, m.Version); err != nil {
+		msg := "cannot remove migration record in migrateDown: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		msg := "cannot execute foreign_key_check in checkForeignKeys: " + err.Error()
+		return errors.New(msg)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		msg := "foreign key constraint violated after migration"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) getSchemaMigrations(tx *sqlx.Tx) ([]SchemaMigration, error) {
+	cols := `version, name, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM schema_migrations ORDER BY version ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getSchemaMigrations: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return []SchemaMigration{}, nil
+		default:
+			msg := "cannot execute statement for getSchemaMigrations: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	defer rows.Close()
+	sms := []SchemaMigration{}
+	for rows.Next() {
+		var sm SchemaMigration
+		err = rows.StructScan(&sm)
+		if err != nil {
+			msg := "cannot unmarshal schema migration from getSchemaMigrations: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		sms = append(sms, sm)
+	}
+	return sms, nil
+}
@@ -0,0 +1,131 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+// testSchema returns the statements that create every table, index and
+// trigger of db, keyed by name.
+func testSchema(t *testing.T, db *Database) map[string]string {
+	t.Helper()
+	rows := []struct {
+		Name string `db:"name"`
+		SQL  string `db:"sql"`
+	}{}
+	err := db.db.Select(&rows, `SELECT name, COALESCE(sql, '') AS sql FROM sqlite_master WHERE name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := map[string]string{}
+	for i := range rows {
+		schema[rows[i].Name] = rows[i].SQL
+	}
+	return schema
+}
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i := range migrations {
+		if migrations[i].Version != int64(i+1) {
+			t.Errorf("migration %d_%s is out of sequence, want version %d", migrations[i].Version, migrations[i].Name, i+1)
+		}
+		if strings.TrimSpace(migrations[i].Up) == "" || strings.TrimSpace(migrations[i].Down) == "" {
+			t.Errorf("migration %d_%s is empty", migrations[i].Version, migrations[i].Name)
+		}
+	}
+}
+
+// TestMigrateUpDownUp applies every migration, reverts them one at a time and
+// applies them again, which must give the same schema.
+func TestMigrateUpDownUp(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].Version
+	db := openTestDatabase(t)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	version, err := db.GetSchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latest {
+		t.Fatalf("schema version is %d after Migrate, want %d", version, latest)
+	}
+	want := testSchema(t, db)
+	for v := latest - 1; v >= 0; v-- {
+		if err := db.MigrateTo(v); err != nil {
+			t.Fatalf("cannot migrate down to %d: %v", v, err)
+		}
+		version, err := db.GetSchemaVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != v {
+			t.Fatalf("schema version is %d, want %d", version, v)
+		}
+	}
+	schema := testSchema(t, db)
+	if len(schema) != 1 || schema["schema_migrations"] == "" {
+		t.Errorf("tables left after reverting every migration: %v", schema)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	got := testSchema(t, db)
+	for name, sql := range want {
+		if got[name] != sql {
+			t.Errorf("%s is\n%s\nafter migrating again, want\n%s", name, got[name], sql)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s was created by migrating again", name)
+		}
+	}
+}
+
+// TestMigrateKeepsData reverts every migration but the first, which creates
+// the tables, and checks that users and posts survive the table rebuilds.
+func TestMigrateKeepsData(t *testing.T) {
+	db := newTestDatabase(t)
+	db_post := createTestPost(t, db, testPost("Kept Post", "<p>kept</p>"))
+	if err := db.MigrateTo(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := db.GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin == nil || !admin.IsAdmin() {
+		t.Errorf("admin is %+v after migrating down and up", admin)
+	}
+	kept, err := db.GetPostByUrlTitle("Kept-Post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept == nil || kept.ID != db_post.ID || kept.Title != db_post.Title {
+		t.Fatalf("post is %+v after migrating down and up, want %+v", kept, db_post)
+	}
+	history, err := db.GetLatestPostHistory(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history == nil || history.Body != "<p>kept</p>" {
+		t.Errorf("latest revision is %+v after migrating down and up", history)
+	}
+}
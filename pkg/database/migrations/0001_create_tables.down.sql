@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS post_categories;
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS post_history;
+DROP TABLE IF EXISTS category;
+DROP TABLE IF EXISTS tag;
+DROP TABLE IF EXISTS post;
+DROP TABLE IF EXISTS user;
@@ -1,6 +1,4 @@
-PRAGMA foreign_keys = ON;
-
-CREATE TABLE user (
+CREATE TABLE IF NOT EXISTS user (
     id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
     user_name   TEXT    NOT NULL CHECK(TYPEOF(user_name) = 'text'),
     first_name  TEXT    NOT NULL CHECK(TYPEOF(first_name) = 'text'),
@@ -10,7 +8,7 @@ CREATE TABLE user (
     UNIQUE(user_name COLLATE NOCASE)
 );
 
-CREATE TABLE post (
+CREATE TABLE IF NOT EXISTS post (
     id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')                         PRIMARY KEY AUTOINCREMENT,
     url_title   TEXT    NOT NULL CHECK(TYPEOF(url_title) = 'text'),
     user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')                    REFERENCES user(id),
@@ -21,7 +19,7 @@ CREATE TABLE post (
     UNIQUE(url_title COLLATE NOCASE)
 );
 
-CREATE TABLE tag (
+CREATE TABLE IF NOT EXISTS tag (
     id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
     name        TEXT    NOT NULL CHECK(TYPEOF(name) = 'text'),
     user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')     REFERENCES user(id),
@@ -29,7 +27,7 @@ CREATE TABLE tag (
     UNIQUE(name COLLATE NOCASE)
 );
 
-CREATE TABLE category (
+CREATE TABLE IF NOT EXISTS category (
     id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
     name        TEXT    NOT NULL CHECK(TYPEOF(name) = 'text'),
     user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')     REFERENCES user(id),
@@ -37,7 +35,7 @@ CREATE TABLE category (
     UNIQUE(name COLLATE NOCASE)
 );
 
-CREATE TABLE post_history (
+CREATE TABLE IF NOT EXISTS post_history (
     id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
     post_id     INTEGER NOT NULL CHECK(TYPEOF(post_id) = 'integer')     REFERENCES post(id),
     body        TEXT    NOT NULL CHECK(TYPEOF(body) = 'text'),
@@ -45,7 +43,7 @@ CREATE TABLE post_history (
     insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer))
 );
 
-CREATE TABLE post_tags (
+CREATE TABLE IF NOT EXISTS post_tags (
     id              INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')              PRIMARY KEY AUTOINCREMENT,
     post_history_id INTEGER NOT NULL CHECK(TYPEOF(post_history_id) = 'integer') REFERENCES post_history(id),
     tag_id          INTEGER NOT NULL CHECK(TYPEOF(tag_id) = 'integer')          REFERENCES tag(id),
@@ -53,7 +51,7 @@ CREATE TABLE post_tags (
     UNIQUE(post_history_id, tag_id)
 );
 
-CREATE TABLE post_categories (
+CREATE TABLE IF NOT EXISTS post_categories (
     id              INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')               PRIMARY KEY AUTOINCREMENT,
     post_history_id INTEGER NOT NULL CHECK(TYPEOF(post_history_id) = 'integer')  REFERENCES post_history(id),
     category_id          INTEGER NOT NULL CHECK(TYPEOF(category_id) = 'integer') REFERENCES category(id),
@@ -0,0 +1,7 @@
+DELETE FROM user
+WHERE LOWER(user_name) = "admin"
+AND id NOT IN (
+    SELECT user_id FROM post
+    UNION SELECT user_id FROM tag
+    UNION SELECT user_id FROM category
+);
@@ -0,0 +1,7 @@
+INSERT INTO user (user_name, first_name, last_name)
+SELECT LOWER("Admin"), "ADMIN", "ADMIN"
+WHERE NOT EXISTS (
+    SELECT id
+    FROM user
+    WHERE LOWER(user_name) = "admin"
+);
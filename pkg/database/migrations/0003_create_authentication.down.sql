@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS api_token;
+DROP TABLE IF EXISTS session;
+
+-- SQLite cannot drop a column, so the user table is rebuilt without
+-- password_hash while the tables referencing it are left untouched.
+-- motdoftheday: foreign_keys=off
+
+CREATE TABLE user_rebuild (
+    id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
+    user_name   TEXT    NOT NULL CHECK(TYPEOF(user_name) = 'text'),
+    first_name  TEXT    NOT NULL CHECK(TYPEOF(first_name) = 'text'),
+    last_name   TEXT    NOT NULL CHECK(TYPEOF(last_name) = 'text'),
+    update_time INTEGER NOT NULL CHECK(TYPEOF(update_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    UNIQUE(user_name COLLATE NOCASE)
+);
+
+INSERT INTO user_rebuild (id, user_name, first_name, last_name, update_time, insert_time)
+SELECT id, user_name, first_name, last_name, update_time, insert_time
+FROM user;
+
+DROP TABLE user;
+
+ALTER TABLE user_rebuild RENAME TO user;
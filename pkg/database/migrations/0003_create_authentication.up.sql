@@ -0,0 +1,20 @@
+ALTER TABLE user ADD COLUMN password_hash TEXT NOT NULL CHECK(TYPEOF(password_hash) = 'text') DEFAULT '';
+
+CREATE TABLE session (
+    id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
+    token_hash  TEXT    NOT NULL CHECK(TYPEOF(token_hash) = 'text'),
+    user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')     REFERENCES user(id),
+    expire_time INTEGER NOT NULL CHECK(TYPEOF(expire_time) = 'integer'),
+    insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    UNIQUE(token_hash)
+);
+
+CREATE TABLE api_token (
+    id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')          PRIMARY KEY AUTOINCREMENT,
+    name        TEXT    NOT NULL CHECK(TYPEOF(name) = 'text'),
+    token_hash  TEXT    NOT NULL CHECK(TYPEOF(token_hash) = 'text'),
+    user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')     REFERENCES user(id),
+    insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer') DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    UNIQUE(token_hash),
+    UNIQUE(user_id, name COLLATE NOCASE)
+);
@@ -0,0 +1,3 @@
+-- The format a post is published in. An empty format falls back to the
+-- format set in the processors config.
+ALTER TABLE post ADD COLUMN format TEXT NOT NULL CHECK(TYPEOF(format) = 'text' AND format IN ('', 'html', 'markdown')) DEFAULT '';
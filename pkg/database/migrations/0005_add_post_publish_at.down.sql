@@ -0,0 +1,23 @@
+-- SQLite cannot drop a column, so the post table is rebuilt without
+-- publish_at while the tables referencing it are left untouched.
+-- motdoftheday: foreign_keys=off
+
+CREATE TABLE post_rebuild (
+    id          INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')                         PRIMARY KEY AUTOINCREMENT,
+    url_title   TEXT    NOT NULL CHECK(TYPEOF(url_title) = 'text'),
+    user_id     INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')                    REFERENCES user(id),
+    title       TEXT    NOT NULL CHECK(TYPEOF(title) = 'text'),
+    posted      INTEGER NOT NULL CHECK(TYPEOF(posted) = 'integer' AND posted IN (0,1)) DEFAULT 0,
+    update_time INTEGER NOT NULL CHECK(TYPEOF(update_time) = 'integer')                DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    insert_time INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer')                DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    format      TEXT    NOT NULL CHECK(TYPEOF(format) = 'text' AND format IN ('', 'html', 'markdown')) DEFAULT '',
+    UNIQUE(url_title COLLATE NOCASE)
+);
+
+INSERT INTO post_rebuild (id, url_title, user_id, title, posted, update_time, insert_time, format)
+SELECT id, url_title, user_id, title, posted, update_time, insert_time, format
+FROM post;
+
+DROP TABLE post;
+
+ALTER TABLE post_rebuild RENAME TO post;
@@ -0,0 +1,3 @@
+-- When set on a draft, the scheduler publishes the post once this unix time
+-- has passed.
+ALTER TABLE post ADD COLUMN publish_at INTEGER CHECK(publish_at IS NULL OR TYPEOF(publish_at) = 'integer') DEFAULT NULL;
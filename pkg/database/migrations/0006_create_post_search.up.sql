@@ -0,0 +1,9 @@
+-- Full text index over every revision of a post. The rowid is the
+-- post_history id and body holds the revision with its HTML stripped, so the
+-- index is maintained by the application rather than by triggers.
+CREATE VIRTUAL TABLE post_search USING fts5(
+    title,
+    body,
+    post_id UNINDEXED,
+    tokenize = 'porter unicode61'
+);
@@ -0,0 +1,19 @@
+-- The file name a post was generated as, kept when a post is unpublished so
+-- that publishing it again replaces the same file.
+ALTER TABLE post ADD COLUMN published_name TEXT NOT NULL CHECK(TYPEOF(published_name) = 'text') DEFAULT '';
+
+-- Posts published before this migration were named after the date of their
+-- latest revision, or of publish_at when they were scheduled.
+UPDATE post
+SET published_name = (
+    SELECT strftime('%Y', t, 'unixepoch') || '-' ||
+           CAST(strftime('%m', t, 'unixepoch') AS INTEGER) || '-' ||
+           CAST(strftime('%d', t, 'unixepoch') AS INTEGER) || '-' ||
+           post.url_title || '.md'
+    FROM (
+        SELECT COALESCE(post.publish_at, MAX(insert_time)) AS t
+        FROM post_history
+        WHERE post_history.post_id = post.id
+    )
+)
+WHERE posted = 1;
@@ -0,0 +1,13 @@
+-- Files uploaded from the editor. The hash is the sha256 of data, so an
+-- upload of a file that is already stored returns the existing asset.
+CREATE TABLE asset (
+    id           INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')           PRIMARY KEY AUTOINCREMENT,
+    hash         TEXT    NOT NULL CHECK(TYPEOF(hash) = 'text'),
+    name         TEXT    NOT NULL CHECK(TYPEOF(name) = 'text'),
+    content_type TEXT    NOT NULL CHECK(TYPEOF(content_type) = 'text'),
+    size         INTEGER NOT NULL CHECK(TYPEOF(size) = 'integer'),
+    data         BLOB    NOT NULL CHECK(TYPEOF(data) = 'blob'),
+    user_id      INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')      REFERENCES user(id),
+    insert_time  INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer')  DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    UNIQUE(hash)
+);
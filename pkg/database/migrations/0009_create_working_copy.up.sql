@@ -0,0 +1,18 @@
+-- The autosaved state of a post being edited, kept apart from post_history so
+-- that autosaving does not add a revision each time. There is at most one per
+-- post, keyed by url_title so that a post can be autosaved before its first
+-- save, and it is removed once a revision of the post is saved.
+CREATE TABLE working_copy (
+    id              INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')              PRIMARY KEY AUTOINCREMENT,
+    url_title       TEXT    NOT NULL CHECK(TYPEOF(url_title) = 'text'),
+    title           TEXT    NOT NULL CHECK(TYPEOF(title) = 'text'),
+    body            TEXT    NOT NULL CHECK(TYPEOF(body) = 'text'),
+    categories      TEXT    NOT NULL CHECK(TYPEOF(categories) = 'text'),
+    tags            TEXT    NOT NULL CHECK(TYPEOF(tags) = 'text'),
+    format          TEXT    NOT NULL CHECK(TYPEOF(format) = 'text'),
+    post_history_id INTEGER          CHECK(post_history_id IS NULL OR TYPEOF(post_history_id) = 'integer'),
+    user_id         INTEGER NOT NULL CHECK(TYPEOF(user_id) = 'integer')         REFERENCES user(id),
+    update_time     INTEGER NOT NULL CHECK(TYPEOF(update_time) = 'integer')     DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    insert_time     INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer')     DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    UNIQUE(url_title COLLATE NOCASE)
+);
@@ -0,0 +1,22 @@
+-- SQLite cannot drop a column, so the user table is rebuilt without admin
+-- while the tables referencing it are left untouched.
+-- motdoftheday: foreign_keys=off
+
+CREATE TABLE user_rebuild (
+    id            INTEGER NOT NULL CHECK(TYPEOF(id) = 'integer')            PRIMARY KEY AUTOINCREMENT,
+    user_name     TEXT    NOT NULL CHECK(TYPEOF(user_name) = 'text'),
+    first_name    TEXT    NOT NULL CHECK(TYPEOF(first_name) = 'text'),
+    last_name     TEXT    NOT NULL CHECK(TYPEOF(last_name) = 'text'),
+    update_time   INTEGER NOT NULL CHECK(TYPEOF(update_time) = 'integer')   DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    insert_time   INTEGER NOT NULL CHECK(TYPEOF(insert_time) = 'integer')   DEFAULT (CAST(strftime('%s', 'now') as integer)),
+    password_hash TEXT    NOT NULL CHECK(TYPEOF(password_hash) = 'text')    DEFAULT '',
+    UNIQUE(user_name COLLATE NOCASE)
+);
+
+INSERT INTO user_rebuild (id, user_name, first_name, last_name, update_time, insert_time, password_hash)
+SELECT id, user_name, first_name, last_name, update_time, insert_time, password_hash
+FROM user;
+
+DROP TABLE user;
+
+ALTER TABLE user_rebuild RENAME TO user;
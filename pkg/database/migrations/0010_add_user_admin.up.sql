@@ -0,0 +1,5 @@
+-- Admins can manage other users and export the database. The admin user
+-- created by the migrations is the first one.
+ALTER TABLE user ADD COLUMN admin INTEGER NOT NULL CHECK(TYPEOF(admin) = 'integer' AND admin IN (0,1)) DEFAULT 0;
+
+UPDATE user SET admin = 1 WHERE LOWER(user_name) = 'admin';
@@ -4,19 +4,23 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
 type Post struct {
-	ID         int64  `db:"id"`
-	UrlTitle   string `db:"url_title"`
-	UserID     int64  `db:"user_id"`
-	Title      string `db:"title"`
-	Posted     int64  `db:"posted"`
-	UpdateTime int64  `db:"update_time"`
-	InsertTime int64  `db:"insert_time"`
+	ID            int64  `db:"id" json:"id"`
+	UrlTitle      string `db:"url_title" json:"url_title"`
+	UserID        int64  `db:"user_id" json:"user_id"`
+	Title         string `db:"title" json:"title"`
+	Posted        int64  `db:"posted" json:"posted"`
+	Format        string `db:"format" json:"format"`
+	PublishAt     *int64 `db:"publish_at" json:"publish_at"`
+	PublishedName string `db:"published_name" json:"published_name"`
+	UpdateTime    int64  `db:"update_time" json:"update_time"`
+	InsertTime    int64  `db:"insert_time" json:"insert_time"`
 }
 
 type BOOL int64
@@ -39,7 +43,7 @@ func DB_FALSE() BOOL {
 }
 
 func (database *Database) GetPostById(id int64) (*Post, error) {
-	cols := `id, url_title, user_id, title, posted, update_time, insert_time`
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post WHERE id = $1`, cols)
 	stmt, err := database.db.Preparex(query)
 	if err != nil {
@@ -180,11 +184,11 @@ func (database *Database) GetCompletePost(post *Post) (*CompletePost, error) {
 	return complete_post, nil
 }
 
-func (database *Database) CreatePost(post post.Post, posted BOOL) error {
+func (database *Database) CreatePost(post post.Post, posted BOOL) (*int64, error) {
 	err := post.Validate()
 	if err != nil {
 		msg := "cannot validate post in CreatePost: " + err.Error()
-		return errors.New(msg)
+		return nil, errors.New(msg)
 	}
 	tx, err := database.db.Beginx()
 	if err != nil {
@@ -192,9 +196,28 @@ func (database *Database) CreatePost(post post.Post, posted BOOL) error {
 		err = tx.Rollback()
 		if err != nil {
 			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
+			return nil, errors.New(fatal)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
+	}
+	author, err := database.getUserByUsername(tx, post.Author())
+	if err != nil {
+		msg := "cannot get author in CreatePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if author == nil {
+		msg := "no user '" + post.Author() + "' found in CreatePost"
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
 	}
 	found, p, err := database.getPost(tx, post)
 	if err != nil {
@@ -202,94 +225,63 @@ func (database *Database) CreatePost(post post.Post, posted BOOL) error {
 		err = tx.Rollback()
 		if err != nil {
 			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
+			return nil, errors.New(fatal)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
+	}
+	err = database.checkBaseRevision(tx, post, found, p)
+	if err != nil {
+		rollback_err := tx.Rollback()
+		if rollback_err != nil {
+			fatal := "cannot rollback in CreatePost: " + err.Error() + ": " + rollback_err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, err
 	}
 	if BOOL(p.Posted) == db_TRUE {
 		msg := "Post already posted and cannot be edited in CreatePost"
 		err = tx.Rollback()
 		if err != nil {
 			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
+			return nil, errors.New(fatal)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
 	}
 	var post_id int64
 	if found {
-		err = database.updatePost(tx, p, posted)
+		err = database.updatePost(tx, p, post, posted)
 		if err != nil {
 			msg := "cannot update post in CreatePost: " + err.Error()
 			err = tx.Rollback()
 			if err != nil {
 				fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-				return errors.New(fatal)
+				return nil, errors.New(fatal)
 			}
-			return errors.New(msg)
+			return nil, errors.New(msg)
 		}
 		post_id = p.ID
 	} else {
-		id, err := database.insertPost(tx, post, posted)
+		id, err := database.insertPost(tx, post, author.ID, posted)
 		if err != nil {
 			msg := "cannot insert new post in CreatePost: " + err.Error()
 			err = tx.Rollback()
 			if err != nil {
 				fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-				return errors.New(fatal)
+				return nil, errors.New(fatal)
 			}
-			return errors.New(msg)
+			return nil, errors.New(msg)
 		}
 		post_id = *id
 	}
-	post_history_id, err := database.insertPostHistory(tx, post_id, post)
-	if err != nil {
-		msg := "cannot insert post history in CreatePost: " + err.Error()
-		err = tx.Rollback()
-		if err != nil {
-			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
-		}
-		return errors.New(msg)
-	}
-	category_ids, err := database.insertCategories(tx, post)
-	if err != nil {
-		msg := "cannot insert categories in CreatePost: " + err.Error()
-		err = tx.Rollback()
-		if err != nil {
-			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
-		}
-		return errors.New(msg)
-	}
-	tag_ids, err := database.insertTags(tx, post)
-	if err != nil {
-		msg := "cannot insert tags in CreatePost: " + err.Error()
-		err = tx.Rollback()
-		if err != nil {
-			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
-		}
-		return errors.New(msg)
-	}
-	_, err = database.insertPostCategories(tx, *post_history_id, category_ids)
-	if err != nil {
-		msg := "cannot insert post categories in CreatePost: " + err.Error()
-		err = tx.Rollback()
-		if err != nil {
-			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
-		}
-		return errors.New(msg)
-	}
-	_, err = database.insertPostTags(tx, *post_history_id, tag_ids)
+	post_history_id, err := database.insertRevision(tx, post, post_id, author.ID)
 	if err != nil {
-		msg := "cannot insert post tags in CreatePost: " + err.Error()
+		msg := "cannot insert revision in CreatePost: " + err.Error()
 		err = tx.Rollback()
 		if err != nil {
 			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
+			return nil, errors.New(fatal)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
 	}
 	err = tx.Commit()
 	if err != nil {
@@ -297,15 +289,15 @@ func (database *Database) CreatePost(post post.Post, posted BOOL) error {
 		err = tx.Rollback()
 		if err != nil {
 			fatal := "cannot rollback in CreatePost: " + msg + ": " + err.Error()
-			return errors.New(fatal)
+			return nil, errors.New(fatal)
 		}
-		return errors.New(msg)
+		return nil, errors.New(msg)
 	}
-	return nil
+	return post_history_id, nil
 }
 
 func (database *Database) getPostByUrlTitle(tx *sqlx.Tx, url_title string) (*Post, error) {
-	cols := `id, url_title, user_id, title, posted, update_time, insert_time`
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post WHERE LOWER(url_title) = LOWER($1)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -329,7 +321,7 @@ func (database *Database) getPostByUrlTitle(tx *sqlx.Tx, url_title string) (*Pos
 }
 
 func (database *Database) getPostById(tx *sqlx.Tx, id int64) (*Post, error) {
-	cols := `id, url_title, user_id, title, posted, update_time, insert_time`
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post WHERE id = $1`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -354,7 +346,7 @@ func (database *Database) getPostById(tx *sqlx.Tx, id int64) (*Post, error) {
 
 func (database *Database) getPost(tx *sqlx.Tx, post post.Post) (bool, *Post, error) {
 	url_title := post.UrlTitle()
-	cols := `id, url_title, user_id, title, posted, update_time, insert_time`
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post WHERE LOWER(url_title) = LOWER($1)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -378,8 +370,50 @@ func (database *Database) getPost(tx *sqlx.Tx, post post.Post) (bool, *Post, err
 	return found, &p, nil
 }
 
+// ConflictError is returned when a post has changed since the revision an
+// edit was based on. Latest is the newer revision, which is nil when the post
+// no longer exists.
+type ConflictError struct {
+	Base   int64
+	Latest *PostHistory
+}
+
+func (e *ConflictError) Error() string {
+	if e.Latest == nil {
+		return "post edited from revision " + strconv.FormatInt(e.Base, 10) + " no longer exists"
+	}
+	if e.Base == 0 {
+		return "post already exists with revision " + strconv.FormatInt(e.Latest.ID, 10)
+	}
+	return "post has changed since revision " + strconv.FormatInt(e.Base, 10) + ", the latest revision is " + strconv.FormatInt(e.Latest.ID, 10)
+}
+
+// checkBaseRevision returns a ConflictError when the post p, if found, has a
+// newer revision than the one post was edited from.
+func (database *Database) checkBaseRevision(tx *sqlx.Tx, post post.Post, found bool, p *Post) error {
+	if post.PostHistoryID == nil {
+		return nil
+	}
+	base := *post.PostHistoryID
+	if !found {
+		if base != 0 {
+			return &ConflictError{Base: base}
+		}
+		return nil
+	}
+	latest, err := database.getLatestPost(tx, p)
+	if err != nil {
+		msg := "cannot get latest revision in checkBaseRevision: " + err.Error()
+		return errors.New(msg)
+	}
+	if latest.ID != base {
+		return &ConflictError{Base: base, Latest: latest}
+	}
+	return nil
+}
+
 func (database *Database) getPostsByPosted(tx *sqlx.Tx, posted BOOL) ([]Post, error) {
-	cols := `id, url_title, user_id, title, posted, update_time, insert_time`
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post WHERE posted = $1`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -405,17 +439,17 @@ func (database *Database) getPostsByPosted(tx *sqlx.Tx, posted BOOL) ([]Post, er
 	return ps, nil
 }
 
-func (database *Database) insertPost(tx *sqlx.Tx, post post.Post, posted BOOL) (*int64, error) {
+func (database *Database) insertPost(tx *sqlx.Tx, post post.Post, user_id int64, posted BOOL) (*int64, error) {
 	url_title := post.UrlTitle()
-	cols := `url_title, user_id, title, posted`
-	query := fmt.Sprintf(`INSERT INTO post (%s) VALUES(LOWER($1), 1, $2, $3)`, cols)
+	cols := `url_title, user_id, title, posted, format`
+	query := fmt.Sprintf(`INSERT INTO post (%s) VALUES(LOWER($1), $2, $3, $4, $5)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for insertPost: " + err.Error()
 		return nil, errors.New(msg)
 	}
 	defer stmt.Close()
-	res, err := stmt.Exec(url_title, post.Title, posted)
+	res, err := stmt.Exec(url_title, user_id, post.Title, posted, post.Format)
 	if err != nil {
 		msg := "cannot execute query in insertPost: " + err.Error()
 		return nil, errors.New(msg)
@@ -426,7 +460,7 @@ func (database *Database) insertPost(tx *sqlx.Tx, post post.Post, posted BOOL) (
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertPost but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertPost but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	post_id, err := res.LastInsertId()
@@ -437,15 +471,15 @@ func (database *Database) insertPost(tx *sqlx.Tx, post post.Post, posted BOOL) (
 	return &post_id, nil
 }
 
-func (database *Database) updatePost(tx *sqlx.Tx, db_post *Post, posted BOOL) error {
-	query := `UPDATE post SET posted = $1, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $2`
+func (database *Database) updatePost(tx *sqlx.Tx, db_post *Post, post post.Post, posted BOOL) error {
+	query := `UPDATE post SET posted = $1, format = $2, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $3`
 	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for updatePost: " + err.Error()
 		return errors.New(msg)
 	}
 	defer stmt.Close()
-	res, err := stmt.Exec(posted, db_post.ID)
+	res, err := stmt.Exec(posted, post.Format, db_post.ID)
 	if err != nil {
 		msg := "cannot execute query in updatePost: " + err.Error()
 		return errors.New(msg)
@@ -456,7 +490,644 @@ func (database *Database) updatePost(tx *sqlx.Tx, db_post *Post, posted BOOL) er
 		return errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in updatePost but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in updatePost but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) GetPosts() ([]Post, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for GetPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	ps, err := database.getPosts(tx)
+	if err != nil {
+		msg := "cannot get posts in GetPosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetPosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetPosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetPosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return ps, nil
+}
+
+func (database *Database) DeletePost(db_post *Post) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for DeletePost: " + err.Error()
+		return errors.New(msg)
+	}
+	history, err := database.getPostHistory(tx, db_post)
+	if err != nil {
+		msg := "cannot get post history in DeletePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	for i := range history {
+		err = database.deletePostTags(tx, history[i].ID)
+		if err != nil {
+			msg := "cannot delete post tags in DeletePost: " + err.Error()
+			err = tx.Rollback()
+			if err != nil {
+				fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+				return errors.New(fatal)
+			}
+			return errors.New(msg)
+		}
+		err = database.deletePostCategories(tx, history[i].ID)
+		if err != nil {
+			msg := "cannot delete post categories in DeletePost: " + err.Error()
+			err = tx.Rollback()
+			if err != nil {
+				fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+				return errors.New(fatal)
+			}
+			return errors.New(msg)
+		}
+	}
+	err = database.deletePostHistory(tx, db_post.ID)
+	if err != nil {
+		msg := "cannot delete post history in DeletePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = database.deleteWorkingCopy(tx, db_post.UrlTitle)
+	if err != nil {
+		msg := "cannot delete working copy in DeletePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = database.deletePost(tx, db_post.ID)
+	if err != nil {
+		msg := "cannot delete post in DeletePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in DeletePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeletePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) getPosts(tx *sqlx.Tx) ([]Post, error) {
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM post ORDER BY id ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		msg := "cannot execute statement for getPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	ps := []Post{}
+	for rows.Next() {
+		var p Post
+		err = rows.StructScan(&p)
+		if err != nil {
+			msg := "cannot unmarshal post from getPosts: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+func (database *Database) deletePost(tx *sqlx.Tx, post_id int64) error {
+	query := `DELETE FROM post WHERE id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deletePost: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(post_id)
+	if err != nil {
+		msg := "cannot execute query in deletePost: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in deletePost: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in deletePost but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) SchedulePost(db_post *Post, publish_at *int64) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for SchedulePost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updatePostPublishAt(tx, db_post.ID, publish_at)
+	if err != nil {
+		msg := "cannot update publish_at in SchedulePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SchedulePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in SchedulePost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SchedulePost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// GetDuePosts returns the drafts scheduled to be published at or before the
+// given unix time.
+func (database *Database) GetDuePosts(before int64) ([]Post, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for GetDuePosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	ps, err := database.getDuePosts(tx, before)
+	if err != nil {
+		msg := "cannot get due posts in GetDuePosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetDuePosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetDuePosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetDuePosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return ps, nil
+}
+
+// PublishPost marks a post as posted and records the file name it was
+// generated as.
+func (database *Database) PublishPost(db_post *Post, published_name string) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for PublishPost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updatePostPublished(tx, db_post.ID, DB_TRUE(), published_name)
+	if err != nil {
+		msg := "cannot update posted in PublishPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in PublishPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in PublishPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in PublishPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// UnpublishPost turns a posted post back into a draft. Its schedule is cleared
+// so the scheduler does not publish it again, while the published name is
+// kept so that publishing it again replaces the same file.
+func (database *Database) UnpublishPost(db_post *Post) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for UnpublishPost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updatePostPublishAt(tx, db_post.ID, nil)
+	if err != nil {
+		msg := "cannot clear publish_at in UnpublishPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in UnpublishPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = database.updatePostPublished(tx, db_post.ID, DB_FALSE(), db_post.PublishedName)
+	if err != nil {
+		msg := "cannot update posted in UnpublishPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in UnpublishPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in UnpublishPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in UnpublishPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// RevisePublishedPost adds a new revision to a post that is already posted.
+// CreatePost refuses to do this so that drafts cannot silently change a live
+// post.
+func (database *Database) RevisePublishedPost(post post.Post) (*int64, error) {
+	err := post.Validate()
+	if err != nil {
+		msg := "cannot validate post in RevisePublishedPost: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for RevisePublishedPost: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	author, err := database.getUserByUsername(tx, post.Author())
+	if err != nil {
+		msg := "cannot get author in RevisePublishedPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if author == nil {
+		msg := "no user '" + post.Author() + "' found in RevisePublishedPost"
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	found, p, err := database.getPost(tx, post)
+	if err != nil {
+		msg := "cannot get post in RevisePublishedPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if !found || BOOL(p.Posted) != db_TRUE {
+		msg := "Post has not been posted in RevisePublishedPost"
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = database.checkBaseRevision(tx, post, found, p)
+	if err != nil {
+		rollback_err := tx.Rollback()
+		if rollback_err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + err.Error() + ": " + rollback_err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, err
+	}
+	err = database.updatePost(tx, p, post, DB_TRUE())
+	if err != nil {
+		msg := "cannot update post in RevisePublishedPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	post_history_id, err := database.insertRevision(tx, post, p.ID, author.ID)
+	if err != nil {
+		msg := "cannot insert revision in RevisePublishedPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in RevisePublishedPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RevisePublishedPost: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return post_history_id, nil
+}
+
+// ImportPost creates a post that was published outside of the application,
+// such as one that already exists on the site. The post and its revision are
+// dated post_time and the post is marked as posted under published_name.
+func (database *Database) ImportPost(post post.Post, post_time int64, published_name string) error {
+	err := post.Validate()
+	if err != nil {
+		msg := "cannot validate post in ImportPost: " + err.Error()
+		return errors.New(msg)
+	}
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for ImportPost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.importPost(tx, post, post_time, published_name)
+	if err != nil {
+		msg := "cannot import post in ImportPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ImportPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in ImportPost: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in ImportPost: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) getDuePosts(tx *sqlx.Tx, before int64) ([]Post, error) {
+	cols := `id, url_title, user_id, title, posted, format, publish_at, published_name, update_time, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM post WHERE posted = $1 AND publish_at IS NOT NULL AND publish_at <= $2 ORDER BY publish_at ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getDuePosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx(DB_FALSE(), before)
+	if err != nil {
+		msg := "cannot execute statement for getDuePosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	ps := []Post{}
+	for rows.Next() {
+		var p Post
+		err = rows.StructScan(&p)
+		if err != nil {
+			msg := "cannot unmarshal post from getDuePosts: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+func (database *Database) updatePostPublishAt(tx *sqlx.Tx, post_id int64, publish_at *int64) error {
+	query := `UPDATE post SET publish_at = $1, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $2`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for updatePostPublishAt: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(publish_at, post_id)
+	if err != nil {
+		msg := "cannot execute query in updatePostPublishAt: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in updatePostPublishAt: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in updatePostPublishAt but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// insertRevision stores the body, tags and categories of post as a new
+// post_history row of post_id, which replaces any working copy of the post.
+func (database *Database) insertRevision(tx *sqlx.Tx, post post.Post, post_id int64, user_id int64) (*int64, error) {
+	post_history_id, err := database.insertPostHistory(tx, post_id, post)
+	if err != nil {
+		msg := "cannot insert post history in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	category_ids, err := database.insertCategories(tx, post, user_id)
+	if err != nil {
+		msg := "cannot insert categories in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	tag_ids, err := database.insertTags(tx, post, user_id)
+	if err != nil {
+		msg := "cannot insert tags in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	_, err = database.insertPostCategories(tx, *post_history_id, category_ids)
+	if err != nil {
+		msg := "cannot insert post categories in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	_, err = database.insertPostTags(tx, *post_history_id, tag_ids)
+	if err != nil {
+		msg := "cannot insert post tags in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	err = database.deleteWorkingCopy(tx, post.UrlTitle())
+	if err != nil {
+		msg := "cannot delete working copy in insertRevision: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return post_history_id, nil
+}
+
+func (database *Database) updatePostPublished(tx *sqlx.Tx, post_id int64, posted BOOL, published_name string) error {
+	query := `UPDATE post SET posted = $1, published_name = $2, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $3`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for updatePostPublished: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(posted, published_name, post_id)
+	if err != nil {
+		msg := "cannot execute query in updatePostPublished: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in updatePostPublished: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in updatePostPublished but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) touchPost(tx *sqlx.Tx, post_id int64) error {
+	query := `UPDATE post SET update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for touchPost: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(post_id)
+	if err != nil {
+		msg := "cannot execute query in touchPost: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in touchPost: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in touchPost but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) importPost(tx *sqlx.Tx, post post.Post, post_time int64, published_name string) error {
+	author, err := database.getUserByUsername(tx, post.Author())
+	if err != nil {
+		msg := "cannot get author in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	if author == nil {
+		msg := "no user '" + post.Author() + "' found in importPost"
+		return errors.New(msg)
+	}
+	found, _, err := database.getPost(tx, post)
+	if err != nil {
+		msg := "cannot get post in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	if found {
+		msg := "post '" + post.UrlTitle() + "' already exists in importPost"
+		return errors.New(msg)
+	}
+	post_id, err := database.insertPost(tx, post, author.ID, DB_TRUE())
+	if err != nil {
+		msg := "cannot insert post in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	_, err = database.insertRevision(tx, post, *post_id, author.ID)
+	if err != nil {
+		msg := "cannot insert revision in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updatePostPublished(tx, *post_id, DB_TRUE(), published_name)
+	if err != nil {
+		msg := "cannot mark post as posted in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updatePostTime(tx, *post_id, post_time)
+	if err != nil {
+		msg := "cannot date post in importPost: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// updatePostTime dates a post and all of its revisions to post_time.
+func (database *Database) updatePostTime(tx *sqlx.Tx, post_id int64, post_time int64) error {
+	query := `UPDATE post SET update_time = $1, insert_time = $1 WHERE id = $2`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for updatePostTime: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_time, post_id)
+	if err != nil {
+		msg := "cannot execute query in updatePostTime: " + err.Error()
+		return errors.New(msg)
+	}
+	query = `UPDATE post_history SET insert_time = $1 WHERE post_id = $2`
+	history_stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare history statement for updatePostTime: " + err.Error()
+		return errors.New(msg)
+	}
+	defer history_stmt.Close()
+	_, err = history_stmt.Exec(post_time, post_id)
+	if err != nil {
+		msg := "cannot execute history query in updatePostTime: " + err.Error()
 		return errors.New(msg)
 	}
 	return nil
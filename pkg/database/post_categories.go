@@ -4,15 +4,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type PostCategory struct {
-	ID         int64 `db:"id"`
-	PostID     int64 `db:"post_history_id"`
-	CategoryID int64 `db:"category_id"`
-	InsertTime int64 `db:"insert_time"`
+	ID         int64 `db:"id" json:"id"`
+	PostID     int64 `db:"post_history_id" json:"post_history_id"`
+	CategoryID int64 `db:"category_id" json:"category_id"`
+	InsertTime int64 `db:"insert_time" json:"insert_time"`
 }
 
 func (database *Database) GetPostCategoryById(id int64) (*PostCategory, error) {
@@ -193,7 +194,7 @@ func (database *Database) insertPostCategory(tx *sqlx.Tx, post_history_id int64,
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertPostCategory but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertPostCategory but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	post_category_id, err := res.LastInsertId()
@@ -203,3 +204,19 @@ func (database *Database) insertPostCategory(tx *sqlx.Tx, post_history_id int64,
 	}
 	return &post_category_id, nil
 }
+
+func (database *Database) deletePostCategories(tx *sqlx.Tx, post_history_id int64) error {
+	query := `DELETE FROM post_categories WHERE post_history_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deletePostCategories: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_history_id)
+	if err != nil {
+		msg := "cannot execute query in deletePostCategories: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -4,17 +4,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
 type PostHistory struct {
-	ID         int64  `db:"id"`
-	PostID     int64  `db:"post_id"`
-	Body       string `db:"body"`
-	Method     string `db:"method"`
-	InsertTime int64  `db:"insert_time"`
+	ID         int64  `db:"id" json:"id"`
+	PostID     int64  `db:"post_id" json:"post_id"`
+	Body       string `db:"body" json:"body"`
+	Method     string `db:"method" json:"method"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
 }
 
 func (database *Database) GetPostHistoryById(post_history_id int64) (*PostHistory, error) {
@@ -85,12 +86,72 @@ func (database *Database) GetLatestPostHistory(post *Post) (*PostHistory, error)
 	return ph, nil
 }
 
+func (database *Database) GetPostHistory(post *Post) ([]PostHistory, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetPostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	phs, err := database.getPostHistory(tx, post)
+	if err != nil {
+		msg := "cannot get post history in GetPostHistory: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetPostHistory: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetPostHistory: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetPostHistory: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return phs, nil
+}
+
+// RestorePostHistory copies post_history along with its tags and categories
+// into a new revision, making it the latest version of its post.
+func (database *Database) RestorePostHistory(post_history *PostHistory, method string) (*PostHistory, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for RestorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	ph, err := database.restorePostHistory(tx, post_history, method)
+	if err != nil {
+		msg := "cannot restore post history in RestorePostHistory: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RestorePostHistory: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in RestorePostHistory: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in RestorePostHistory: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return ph, nil
+}
+
 func (database *Database) getLatestPost(tx *sqlx.Tx, post *Post) (*PostHistory, error) {
 	cols := `MAX(id) AS id, post_id, body, method, insert_time`
 	query := fmt.Sprintf(`
 	SELECT %s
 	FROM post_history
-	WHERE insert_time = (
+	WHERE post_id = $1 AND insert_time = (
 	  SELECT MAX(insert_time)
 	  FROM post_history
 	  WHERE post_id = $1
@@ -119,8 +180,8 @@ func (database *Database) getLatestPost(tx *sqlx.Tx, post *Post) (*PostHistory,
 
 func (database *Database) getPostHistory(tx *sqlx.Tx, post *Post) ([]PostHistory, error) {
 	cols := `id, post_id, body, method, insert_time`
-	query := fmt.Sprintf(`SELECT %s FROM post_history WHERE post_id = $1`, cols)
-	stmt, err := database.db.Preparex(query)
+	query := fmt.Sprintf(`SELECT %s FROM post_history WHERE post_id = $1 ORDER BY id ASC`, cols)
+	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for getLatestPost: " + err.Error()
 		return nil, errors.New(msg)
@@ -170,7 +231,7 @@ func (database *Database) insertPostHistory(tx *sqlx.Tx, post_id int64, post pos
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertPostHistory but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertPostHistory but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	post_history_id, err := res.LastInsertId()
@@ -178,13 +239,18 @@ func (database *Database) insertPostHistory(tx *sqlx.Tx, post_id int64, post pos
 		msg := "cannot get last insert id in insertPostHistory: " + err.Error()
 		return nil, errors.New(msg)
 	}
+	err = database.insertPostSearch(tx, post_history_id, post_id, post.Title, post.Body)
+	if err != nil {
+		msg := "cannot index post history in insertPostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
 	return &post_history_id, nil
 }
 
 func (database *Database) getPostHistoryById(tx *sqlx.Tx, post_history_id int64) (*PostHistory, error) {
 	cols := `id, post_id, body, method, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM post_history WHERE id = $1`, cols)
-	stmt, err := database.db.Preparex(query)
+	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for getPostHistoryById: " + err.Error()
 		return nil, errors.New(msg)
@@ -204,3 +270,78 @@ func (database *Database) getPostHistoryById(tx *sqlx.Tx, post_history_id int64)
 	}
 	return &ph, nil
 }
+
+func (database *Database) deletePostHistory(tx *sqlx.Tx, post_id int64) error {
+	query := `DELETE FROM post_history WHERE post_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deletePostHistory: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_id)
+	if err != nil {
+		msg := "cannot execute query in deletePostHistory: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.deletePostSearch(tx, post_id)
+	if err != nil {
+		msg := "cannot remove post history from search in deletePostHistory: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) restorePostHistory(tx *sqlx.Tx, post_history *PostHistory, method string) (*PostHistory, error) {
+	db_post, err := database.getPostById(tx, post_history.PostID)
+	if err != nil {
+		msg := "cannot get post in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if db_post == nil {
+		msg := "no post found for post history " + strconv.FormatInt(post_history.ID, 10) + " in restorePostHistory"
+		return nil, errors.New(msg)
+	}
+	tags, err := database.getPostHistoryTags(tx, post_history)
+	if err != nil {
+		msg := "cannot get tags in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	categories, err := database.getPostHistoryCategories(tx, post_history)
+	if err != nil {
+		msg := "cannot get categories in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	p := post.New(method, "")
+	p.Title = db_post.Title
+	p.Body = post_history.Body
+	post_history_id, err := database.insertPostHistory(tx, db_post.ID, p)
+	if err != nil {
+		msg := "cannot insert post history in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	tag_ids := []int64{}
+	for i := range tags {
+		tag_ids = append(tag_ids, tags[i].ID)
+	}
+	_, err = database.insertPostTags(tx, *post_history_id, tag_ids)
+	if err != nil {
+		msg := "cannot insert post tags in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	category_ids := []int64{}
+	for i := range categories {
+		category_ids = append(category_ids, categories[i].ID)
+	}
+	_, err = database.insertPostCategories(tx, *post_history_id, category_ids)
+	if err != nil {
+		msg := "cannot insert post categories in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	err = database.touchPost(tx, db_post.ID)
+	if err != nil {
+		msg := "cannot update post in restorePostHistory: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return database.getPostHistoryById(tx, *post_history_id)
+}
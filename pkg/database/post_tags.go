@@ -4,15 +4,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type PostTag struct {
-	ID         int64 `db:"id"`
-	PostID     int64 `db:"post_history_id"`
-	TagID      int64 `db:"tag_id"`
-	InsertTime int64 `db:"insert_time"`
+	ID         int64 `db:"id" json:"id"`
+	PostID     int64 `db:"post_history_id" json:"post_history_id"`
+	TagID      int64 `db:"tag_id" json:"tag_id"`
+	InsertTime int64 `db:"insert_time" json:"insert_time"`
 }
 
 func (database *Database) GetPostHistoryTags(post_history *PostHistory) ([]Tag, error) {
@@ -159,7 +160,7 @@ func (database *Database) insertPostTag(tx *sqlx.Tx, post_history_id int64, tag_
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertPostTag but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertPostTag but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	post_tag_id, err := res.LastInsertId()
@@ -169,3 +170,19 @@ func (database *Database) insertPostTag(tx *sqlx.Tx, post_history_id int64, tag_
 	}
 	return &post_tag_id, nil
 }
+
+func (database *Database) deletePostTags(tx *sqlx.Tx, post_history_id int64) error {
+	query := `DELETE FROM post_tags WHERE post_history_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deletePostTags: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_history_id)
+	if err != nil {
+		msg := "cannot execute query in deletePostTags: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/net/html"
+)
+
+const searchLimit int = 50
+
+// Inline elements do not separate words, so no space is added around them
+// when stripping HTML.
+var inlineElements = map[string]bool{
+	"a":      true,
+	"b":      true,
+	"code":   true,
+	"del":    true,
+	"em":     true,
+	"font":   true,
+	"i":      true,
+	"mark":   true,
+	"s":      true,
+	"small":  true,
+	"span":   true,
+	"strike": true,
+	"strong": true,
+	"sub":    true,
+	"sup":    true,
+	"u":      true,
+}
+
+type SearchFilters struct {
+	Tags       []string
+	Categories []string
+	Posted     *BOOL
+}
+
+type SearchResult struct {
+	Post
+	PostHistoryID int64   `db:"post_history_id" json:"post_history_id"`
+	Snippet       string  `db:"snippet" json:"snippet"`
+	Rank          float64 `db:"rank" json:"rank"`
+}
+
+// SearchPosts runs a full text search over the title and every revision of
+// each post and returns the best matching revision per post. Tag and category
+// filters must all match the latest revision of a post.
+func (database *Database) SearchPosts(query string, filters SearchFilters) ([]SearchResult, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for SearchPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	results, err := database.searchPosts(tx, query, filters)
+	if err != nil {
+		msg := "cannot search posts in SearchPosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SearchPosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in SearchPosts: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SearchPosts: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return results, nil
+}
+
+// SyncSearchIndex rebuilds the search index when it does not cover every
+// post_history row, such as after the index is first created or after rows
+// were inserted outside of the application.
+func (database *Database) SyncSearchIndex() error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "begin transaction for SyncSearchIndex: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.syncSearchIndex(tx)
+	if err != nil {
+		msg := "cannot sync search index in SyncSearchIndex: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SyncSearchIndex: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in SyncSearchIndex: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SyncSearchIndex: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) checkSearchSupport() error {
+	var used int64
+	err := database.db.Get(&used, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`)
+	if err != nil {
+		msg := "cannot check sqlite compile options in checkSearchSupport: " + err.Error()
+		return errors.New(msg)
+	}
+	if used != 1 {
+		msg := "sqlite was built without FTS5, build with -tags sqlite_fts5"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) searchPosts(tx *sqlx.Tx, query string, filters SearchFilters) ([]SearchResult, error) {
+	match := searchMatch(query)
+	if match == "" {
+		return []SearchResult{}, nil
+	}
+	args := []interface{}{match}
+	where := []string{}
+	if filters.Posted != nil {
+		args = append(args, *filters.Posted)
+		where = append(where, `p.posted = $`+strconv.Itoa(len(args)))
+	}
+	latest := `(SELECT MAX(id) FROM post_history WHERE post_id = p.id)`
+	for i := range filters.Tags {
+		args = append(args, filters.Tags[i])
+		where = append(where, fmt.Sprintf(`EXISTS (
+		  SELECT 1 FROM post_tags pt JOIN tag t ON t.id = pt.tag_id
+		  WHERE pt.post_history_id = %s AND LOWER(t.name) = LOWER($%d)
+		)`, latest, len(args)))
+	}
+	for i := range filters.Categories {
+		args = append(args, filters.Categories[i])
+		where = append(where, fmt.Sprintf(`EXISTS (
+		  SELECT 1 FROM post_categories pc JOIN category c ON c.id = pc.category_id
+		  WHERE pc.post_history_id = %s AND LOWER(c.name) = LOWER($%d)
+		)`, latest, len(args)))
+	}
+	conditions := ""
+	if len(where) > 0 {
+		conditions = "WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, searchLimit)
+	cols := `p.id, p.url_title, p.user_id, p.title, p.posted, p.format, p.publish_at, p.published_name, p.update_time, p.insert_time`
+	// SQLite returns the other columns of the row holding MIN(rank), so each
+	// post is listed once with its best matching revision. The LIMIT keeps the
+	// subquery from being flattened, which the FTS5 functions do not allow.
+	q := fmt.Sprintf(`
+	SELECT %s, s.post_history_id, s.snippet, MIN(s.rank) AS rank
+	FROM (
+	  SELECT rowid AS post_history_id, post_id, snippet(post_search, 1, '', '', '...', 16) AS snippet, bm25(post_search) AS rank
+	  FROM post_search
+	  WHERE post_search MATCH $1
+	  LIMIT -1
+	) s
+	JOIN post p ON p.id = s.post_id
+	%s
+	GROUP BY p.id
+	ORDER BY rank ASC
+	LIMIT $%d`, cols, conditions, len(args))
+	stmt, err := tx.Preparex(q)
+	if err != nil {
+		msg := "cannot prepare statement for searchPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx(args...)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return []SearchResult{}, nil
+		default:
+			msg := "cannot execute statement for searchPosts: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	defer rows.Close()
+	results := []SearchResult{}
+	for rows.Next() {
+		var r SearchResult
+		err = rows.StructScan(&r)
+		if err != nil {
+			msg := "cannot unmarshal search result from searchPosts: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		results = append(results, r)
+	}
+	if err = rows.Err(); err != nil {
+		msg := "cannot read search results from searchPosts: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return results, nil
+}
+
+func (database *Database) syncSearchIndex(tx *sqlx.Tx) error {
+	var indexed, revisions int64
+	if err := tx.Get(&indexed, `SELECT COUNT(*) FROM post_search`); err != nil {
+		msg := "cannot count indexed revisions in syncSearchIndex: " + err.Error()
+		return errors.New(msg)
+	}
+	if err := tx.Get(&revisions, `SELECT COUNT(*) FROM post_history`); err != nil {
+		msg := "cannot count revisions in syncSearchIndex: " + err.Error()
+		return errors.New(msg)
+	}
+	if indexed == revisions {
+		return nil
+	}
+	if _, err := tx.Exec(`DELETE FROM post_search`); err != nil {
+		msg := "cannot clear search index in syncSearchIndex: " + err.Error()
+		return errors.New(msg)
+	}
+	query := `
+	SELECT ph.id, ph.post_id, p.title, ph.body
+	FROM post_history ph
+	JOIN post p ON p.id = ph.post_id`
+	rows, err := tx.Queryx(query)
+	if err != nil {
+		msg := "cannot execute query in syncSearchIndex: " + err.Error()
+		return errors.New(msg)
+	}
+	type revision struct {
+		ID     int64  `db:"id"`
+		PostID int64  `db:"post_id"`
+		Title  string `db:"title"`
+		Body   string `db:"body"`
+	}
+	rs := []revision{}
+	for rows.Next() {
+		var r revision
+		if err := rows.StructScan(&r); err != nil {
+			rows.Close()
+			msg := "cannot unmarshal revision from syncSearchIndex: " + err.Error()
+			return errors.New(msg)
+		}
+		rs = append(rs, r)
+	}
+	rows.Close()
+	for i := range rs {
+		err = database.insertPostSearch(tx, rs[i].ID, rs[i].PostID, rs[i].Title, rs[i].Body)
+		if err != nil {
+			msg := "cannot index revision in syncSearchIndex: " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+func (database *Database) insertPostSearch(tx *sqlx.Tx, post_history_id int64, post_id int64, title string, body string) error {
+	cols := `rowid, post_id, title, body`
+	query := fmt.Sprintf(`INSERT INTO post_search (%s) VALUES($1, $2, $3, $4)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for insertPostSearch: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_history_id, post_id, title, stripHTML(body))
+	if err != nil {
+		msg := "cannot execute query in insertPostSearch: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) deletePostSearch(tx *sqlx.Tx, post_id int64) error {
+	query := `DELETE FROM post_search WHERE post_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deletePostSearch: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post_id)
+	if err != nil {
+		msg := "cannot execute query in deletePostSearch: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// searchMatch turns free text into an FTS5 query where every word must match
+// as a prefix, so that input containing FTS5 syntax cannot cause errors.
+func searchMatch(query string) string {
+	terms := []string{}
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.Replace(word, `"`, `""`, -1)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// stripHTML returns the text content of a post body so that markup is not
+// indexed.
+func stripHTML(body string) string {
+	z := html.NewTokenizer(strings.NewReader(body))
+	var sb strings.Builder
+	skip := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return strings.Join(strings.Fields(body), " ")
+			}
+			return strings.Join(strings.Fields(sb.String()), " ")
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+			if !inlineElements[string(name)] {
+				sb.WriteString(" ")
+			}
+		}
+	}
+}
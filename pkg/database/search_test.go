@@ -0,0 +1,116 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"testing"
+)
+
+func TestSearchPosts(t *testing.T) {
+	db := newTestDatabase(t)
+	golang := testPost("Go Tips", `<p>Concurrency <b>pat</b>terns with <script>hidden()</script>channels</p>`)
+	golang.Tags = []string{"Go", "programming"}
+	db_golang := createTestPost(t, db, golang)
+	cooking := testPost("Cooking", `<p>Pasta recipes, and a note on concurrency in the kitchen</p>`)
+	cooking.Categories = []string{"food"}
+	db_cooking := createTestPost(t, db, cooking)
+	if err := db.PublishPost(db_golang, "2020-1-1-Go-Tips.md"); err != nil {
+		t.Fatal(err)
+	}
+	posted := DB_TRUE()
+	drafts := DB_FALSE()
+	tests := []struct {
+		name    string
+		query   string
+		filters SearchFilters
+		want    []int64
+	}{
+		{"empty query", "", SearchFilters{}, []int64{}},
+		{"body word", "pasta", SearchFilters{}, []int64{db_cooking.ID}},
+		{"title word", "tips", SearchFilters{}, []int64{db_golang.ID}},
+		{"prefix", "concurr", SearchFilters{}, []int64{db_golang.ID, db_cooking.ID}},
+		{"split by inline markup", "patterns", SearchFilters{}, []int64{db_golang.ID}},
+		{"script not indexed", "hidden", SearchFilters{}, []int64{}},
+		{"every word", "concurrency kitchen", SearchFilters{}, []int64{db_cooking.ID}},
+		{"quotes", `"concurrency`, SearchFilters{}, []int64{db_golang.ID, db_cooking.ID}},
+		{"fts syntax", "concurrency OR NEAR(", SearchFilters{}, []int64{}},
+		{"tag", "concurrency", SearchFilters{Tags: []string{"go"}}, []int64{db_golang.ID}},
+		{"every tag", "concurrency", SearchFilters{Tags: []string{"go", "test"}}, []int64{}},
+		{"category", "concurrency", SearchFilters{Categories: []string{"Food"}}, []int64{db_cooking.ID}},
+		{"posted", "concurrency", SearchFilters{Posted: &posted}, []int64{db_golang.ID}},
+		{"drafts", "concurrency", SearchFilters{Posted: &drafts}, []int64{db_cooking.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := db.SearchPosts(tt.query, tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[int64]bool{}
+			for i := range results {
+				got[results[i].ID] = true
+			}
+			if len(results) != len(tt.want) || len(got) != len(results) {
+				t.Fatalf("got %d results %v, want posts %v", len(results), results, tt.want)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("post %d was not found in %v", id, results)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchPostsReturnsPosts(t *testing.T) {
+	db := newTestDatabase(t)
+	db_post := createTestPost(t, db, testPost("Published Post", `<p>Some <i>searchable</i> text</p>`))
+	if err := db.PublishPost(db_post, "2020-1-1-Published-Post.md"); err != nil {
+		t.Fatal(err)
+	}
+	db_post, err := db.GetPostById(db_post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := db.GetLatestPostHistory(db_post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := db.SearchPosts("searchable", SearchFilters{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Post != *db_post {
+		t.Errorf("result post is %+v, want %+v", results[0].Post, *db_post)
+	}
+	if results[0].PublishedName != "2020-1-1-Published-Post.md" {
+		t.Errorf("result published name is %q", results[0].PublishedName)
+	}
+	if results[0].PostHistoryID != history.ID {
+		t.Errorf("result revision is %d, want %d", results[0].PostHistoryID, history.ID)
+	}
+	if results[0].Snippet != "Published Post" && results[0].Snippet != "Some searchable text" {
+		t.Errorf("snippet %q is not the text of the post", results[0].Snippet)
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`plain  text`, "plain text"},
+		{`<p>one</p><p>two</p>`, "one two"},
+		{`<b>bo</b>ld <span>te</span>xt`, "bold text"},
+		{`<p>a<script>var b;</script><style>p {}</style>c</p>`, "a c"},
+		{`a &amp; b &lt;c&gt;`, "a & b <c>"},
+	}
+	for _, tt := range tests {
+		if got := stripHTML(tt.body); got != tt.want {
+			t.Errorf("stripHTML(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Session struct {
+	ID         int64  `db:"id" json:"id"`
+	TokenHash  string `db:"token_hash" json:"-"`
+	UserID     int64  `db:"user_id" json:"user_id"`
+	ExpireTime int64  `db:"expire_time" json:"expire_time"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
+}
+
+func (database *Database) CreateSession(db_user *User, token_hash string, expire_time int64) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for CreateSession: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.deleteExpiredSessions(tx)
+	if err != nil {
+		msg := "cannot delete expired sessions in CreateSession: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateSession: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	_, err = database.insertSession(tx, db_user.ID, token_hash, expire_time)
+	if err != nil {
+		msg := "cannot insert session in CreateSession: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateSession: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in CreateSession: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateSession: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// GetSessionByTokenHash only returns sessions that have not expired yet.
+func (database *Database) GetSessionByTokenHash(token_hash string) (*Session, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetSessionByTokenHash: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	s, err := database.getSessionByTokenHash(tx, token_hash)
+	if err != nil {
+		msg := "cannot get session in GetSessionByTokenHash: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetSessionByTokenHash: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetSessionByTokenHash: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetSessionByTokenHash: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return s, nil
+}
+
+func (database *Database) DeleteSession(token_hash string) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for DeleteSession: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.deleteSession(tx, token_hash)
+	if err != nil {
+		msg := "cannot delete session in DeleteSession: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteSession: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in DeleteSession: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteSession: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) getSessionByTokenHash(tx *sqlx.Tx, token_hash string) (*Session, error) {
+	cols := `id, token_hash, user_id, expire_time, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM session WHERE token_hash = $1 AND expire_time > CAST(strftime('%%s', 'now') as integer)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getSessionByTokenHash: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(token_hash)
+	var s Session
+	err = row.StructScan(&s)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal session from getSessionByTokenHash: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &s, nil
+}
+
+func (database *Database) insertSession(tx *sqlx.Tx, user_id int64, token_hash string, expire_time int64) (*int64, error) {
+	cols := `token_hash, user_id, expire_time`
+	query := fmt.Sprintf(`INSERT INTO session (%s) VALUES($1, $2, $3)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for insertSession: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(token_hash, user_id, expire_time)
+	if err != nil {
+		msg := "cannot execute query in insertSession: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in insertSession: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in insertSession but " + strconv.FormatInt(rows, 10) + " rows were"
+		return nil, errors.New(msg)
+	}
+	session_id, err := res.LastInsertId()
+	if err != nil {
+		msg := "cannot get last insert id in insertSession: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return &session_id, nil
+}
+
+func (database *Database) deleteSession(tx *sqlx.Tx, token_hash string) error {
+	query := `DELETE FROM session WHERE token_hash = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteSession: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(token_hash)
+	if err != nil {
+		msg := "cannot execute query in deleteSession: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) deleteExpiredSessions(tx *sqlx.Tx) error {
+	query := `DELETE FROM session WHERE expire_time <= CAST(strftime('%s', 'now') as integer)`
+	_, err := tx.Exec(query)
+	if err != nil {
+		msg := "cannot execute query in deleteExpiredSessions: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) deleteUserSessions(tx *sqlx.Tx, user_id int64) error {
+	query := `DELETE FROM session WHERE user_id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteUserSessions: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(user_id)
+	if err != nil {
+		msg := "cannot execute query in deleteUserSessions: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -4,16 +4,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
 type Tag struct {
-	ID         int64  `db:"id"`
-	Name       string `db:"name"`
-	UserID     int64  `db:"user_id"`
-	InsertTime int64  `db:"insert_time"`
+	ID         int64  `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	UserID     int64  `db:"user_id" json:"user_id"`
+	InsertTime int64  `db:"insert_time" json:"insert_time"`
 }
 
 func (database *Database) GetTagById(tag_id int64) (*Tag, error) {
@@ -132,7 +133,7 @@ func (database *Database) getTagByID(tx *sqlx.Tx, tag_id int64) (*Tag, error) {
 	return &t, nil
 }
 
-func (database *Database) insertTags(tx *sqlx.Tx, post post.Post) ([]int64, error) {
+func (database *Database) insertTags(tx *sqlx.Tx, post post.Post, user_id int64) ([]int64, error) {
 	url_tags := post.UrlTags()
 	tag_ids := []int64{}
 	for i := range url_tags {
@@ -145,7 +146,7 @@ func (database *Database) insertTags(tx *sqlx.Tx, post post.Post) ([]int64, erro
 		if tag != nil {
 			tag_ids = append(tag_ids, tag.ID)
 		} else {
-			tag_id, err := database.insertTag(tx, name)
+			tag_id, err := database.insertTag(tx, name, user_id)
 			if err != nil {
 				msg := "cannot insert tag for insertTags: " + err.Error()
 				return nil, errors.New(msg)
@@ -156,16 +157,16 @@ func (database *Database) insertTags(tx *sqlx.Tx, post post.Post) ([]int64, erro
 	return tag_ids, nil
 }
 
-func (database *Database) insertTag(tx *sqlx.Tx, name string) (*int64, error) {
+func (database *Database) insertTag(tx *sqlx.Tx, name string, user_id int64) (*int64, error) {
 	cols := `user_id, name`
-	query := fmt.Sprintf(`INSERT INTO tag (%s) VALUES(1, $1)`, cols)
+	query := fmt.Sprintf(`INSERT INTO tag (%s) VALUES($1, $2)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
 		msg := "cannot prepare statement for insertTag: " + err.Error()
 		return nil, errors.New(msg)
 	}
 	defer stmt.Close()
-	res, err := stmt.Exec(name)
+	res, err := stmt.Exec(user_id, name)
 	if err != nil {
 		msg := "cannot execute query in insertTag: " + err.Error()
 		return nil, errors.New(msg)
@@ -176,7 +177,7 @@ func (database *Database) insertTag(tx *sqlx.Tx, name string) (*int64, error) {
 		return nil, errors.New(msg)
 	}
 	if rows != 1 {
-		msg := "expected 1 row to be affected in insertTag but " + string(rows) + " rows were: " + err.Error()
+		msg := "expected 1 row to be affected in insertTag but " + strconv.FormatInt(rows, 10) + " rows were"
 		return nil, errors.New(msg)
 	}
 	tag_id, err := res.LastInsertId()
@@ -186,3 +187,60 @@ func (database *Database) insertTag(tx *sqlx.Tx, name string) (*int64, error) {
 	}
 	return &tag_id, nil
 }
+
+func (database *Database) GetTags() ([]Tag, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetTags: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	ts, err := database.getTags(tx)
+	if err != nil {
+		msg := "cannot get tags in GetTags: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetTags: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetTags: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetTags: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return ts, nil
+}
+
+func (database *Database) getTags(tx *sqlx.Tx) ([]Tag, error) {
+	cols := `id, name, user_id, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM tag ORDER BY name ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getTags: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		msg := "cannot execute statement for getTags: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	ts := []Tag{}
+	for rows.Next() {
+		var t Tag
+		err = rows.StructScan(&t)
+		if err != nil {
+			msg := "cannot unmarshal tag from getTags: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
@@ -4,17 +4,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
 )
 
 type User struct {
-	ID         int64  `db:"id"`
-	Username   string `db:"user_name"`
-	Firstname  string `db:"first_name"`
-	Lastname   string `db:"last_name"`
-	UpdateTime int64  `db:"update_time"`
-	InsertTime int64  `db:"insert_time"`
+	ID           int64  `db:"id" json:"id"`
+	Username     string `db:"user_name" json:"user_name"`
+	Firstname    string `db:"first_name" json:"first_name"`
+	Lastname     string `db:"last_name" json:"last_name"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	Admin        int64  `db:"admin" json:"admin"`
+	UpdateTime   int64  `db:"update_time" json:"update_time"`
+	InsertTime   int64  `db:"insert_time" json:"insert_time"`
+}
+
+// IsAdmin reports whether the user can manage other users and export the
+// database.
+func (u *User) IsAdmin() bool {
+	return u.Admin == DB_TRUE().Value()
 }
 
 func (database *Database) GetUserById(id int64) (*User, error) {
@@ -85,8 +96,218 @@ func (database *Database) GetUserByUsername(username string) (*User, error) {
 	return u, nil
 }
 
+func (database *Database) GetUsers() ([]User, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetUsers: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	us, err := database.getUsers(tx)
+	if err != nil {
+		msg := "cannot get users in GetUsers: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetUsers: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetUsers: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetUsers: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return us, nil
+}
+
+func (database *Database) CreateUser(u user.User) (*User, error) {
+	err := u.Validate()
+	if err != nil {
+		msg := "cannot validate user in CreateUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for CreateUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	existing, err := database.getUserByUsername(tx, u.Username)
+	if err != nil {
+		msg := "cannot get user in CreateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateUser: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if existing != nil {
+		msg := "user '" + u.Username + "' already exists in CreateUser"
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateUser: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	user_id, err := database.insertUser(tx, u)
+	if err != nil {
+		msg := "cannot insert user in CreateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateUser: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	created, err := database.getUserById(tx, *user_id)
+	if err != nil {
+		msg := "cannot get created user in CreateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateUser: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in CreateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in CreateUser: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return created, nil
+}
+
+func (database *Database) UpdateUser(db_user *User, u user.User) error {
+	err := u.Validate()
+	if err != nil {
+		msg := "cannot validate user in UpdateUser: " + err.Error()
+		return errors.New(msg)
+	}
+	if !strings.EqualFold(db_user.Username, u.Username) {
+		msg := "user name cannot be changed in UpdateUser"
+		return errors.New(msg)
+	}
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for UpdateUser: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updateUser(tx, db_user.ID, u)
+	if err != nil {
+		msg := "cannot update user in UpdateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in UpdateUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in UpdateUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in UpdateUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) SetUserPassword(db_user *User, password_hash string) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for SetUserPassword: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.updateUserPassword(tx, db_user.ID, password_hash)
+	if err != nil {
+		msg := "cannot update password in SetUserPassword: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SetUserPassword: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in SetUserPassword: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SetUserPassword: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// DeleteUser removes a user that has not authored any posts, tags or
+// categories.
+func (database *Database) DeleteUser(db_user *User) error {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for DeleteUser: " + err.Error()
+		return errors.New(msg)
+	}
+	err = database.deleteUserSessions(tx, db_user.ID)
+	if err != nil {
+		msg := "cannot delete sessions in DeleteUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = database.deleteUserApiTokens(tx, db_user.ID)
+	if err != nil {
+		msg := "cannot delete api tokens in DeleteUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = database.deleteUser(tx, db_user.ID)
+	if err != nil {
+		msg := "cannot delete user in DeleteUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in DeleteUser: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in DeleteUser: " + msg + ": " + err.Error()
+			return errors.New(fatal)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
 func (database *Database) getUserById(tx *sqlx.Tx, id int64) (*User, error) {
-	cols := `id, user_name, first_name, last_name, update_time, insert_time`
+	cols := `id, user_name, first_name, last_name, password_hash, admin, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM user WHERE id = $1`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -110,7 +331,7 @@ func (database *Database) getUserById(tx *sqlx.Tx, id int64) (*User, error) {
 }
 
 func (database *Database) getUserByUsername(tx *sqlx.Tx, username string) (*User, error) {
-	cols := `id, user_name, first_name, last_name, update_time, insert_time`
+	cols := `id, user_name, first_name, last_name, password_hash, admin, update_time, insert_time`
 	query := fmt.Sprintf(`SELECT %s FROM user WHERE LOWER(user_name) = LOWER($1)`, cols)
 	stmt, err := tx.Preparex(query)
 	if err != nil {
@@ -132,3 +353,137 @@ func (database *Database) getUserByUsername(tx *sqlx.Tx, username string) (*User
 	}
 	return &u, nil
 }
+
+func (database *Database) getUsers(tx *sqlx.Tx) ([]User, error) {
+	cols := `id, user_name, first_name, last_name, password_hash, admin, update_time, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM user ORDER BY id ASC`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getUsers: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Queryx()
+	if err != nil {
+		msg := "cannot execute statement for getUsers: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+	us := []User{}
+	for rows.Next() {
+		var u User
+		err = rows.StructScan(&u)
+		if err != nil {
+			msg := "cannot unmarshal user from getUsers: " + err.Error()
+			return nil, errors.New(msg)
+		}
+		us = append(us, u)
+	}
+	return us, nil
+}
+
+func (database *Database) insertUser(tx *sqlx.Tx, u user.User) (*int64, error) {
+	cols := `user_name, first_name, last_name`
+	query := fmt.Sprintf(`INSERT INTO user (%s) VALUES(LOWER($1), $2, $3)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for insertUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(u.Username, u.Firstname, u.Lastname)
+	if err != nil {
+		msg := "cannot execute query in insertUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in insertUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in insertUser but " + strconv.FormatInt(rows, 10) + " rows were"
+		return nil, errors.New(msg)
+	}
+	user_id, err := res.LastInsertId()
+	if err != nil {
+		msg := "cannot get last insert id in insertUser: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return &user_id, nil
+}
+
+func (database *Database) updateUser(tx *sqlx.Tx, user_id int64, u user.User) error {
+	query := `UPDATE user SET first_name = $1, last_name = $2, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $3`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for updateUser: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(u.Firstname, u.Lastname, user_id)
+	if err != nil {
+		msg := "cannot execute query in updateUser: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in updateUser: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in updateUser but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) updateUserPassword(tx *sqlx.Tx, user_id int64, password_hash string) error {
+	query := `UPDATE user SET password_hash = $1, update_time = (CAST(strftime('%s', 'now') as integer)) WHERE id = $2`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for updateUserPassword: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(password_hash, user_id)
+	if err != nil {
+		msg := "cannot execute query in updateUserPassword: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in updateUserPassword: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in updateUserPassword but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (database *Database) deleteUser(tx *sqlx.Tx, user_id int64) error {
+	query := `DELETE FROM user WHERE id = $1`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteUser: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	res, err := stmt.Exec(user_id)
+	if err != nil {
+		msg := "cannot execute query in deleteUser: " + err.Error()
+		return errors.New(msg)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		msg := "cannot get affected rows in deleteUser: " + err.Error()
+		return errors.New(msg)
+	}
+	if rows != 1 {
+		msg := "expected 1 row to be affected in deleteUser but " + strconv.FormatInt(rows, 10) + " rows were"
+		return errors.New(msg)
+	}
+	return nil
+}
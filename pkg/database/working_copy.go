@@ -0,0 +1,240 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// WorkingCopy is the autosaved state of a post being edited. PostHistoryID is
+// the revision it was edited from, nil for a post that has not been saved.
+type WorkingCopy struct {
+	ID            int64      `db:"id" json:"id"`
+	UrlTitle      string     `db:"url_title" json:"url_title"`
+	Title         string     `db:"title" json:"title"`
+	Body          string     `db:"body" json:"body"`
+	Categories    StringList `db:"categories" json:"categories"`
+	Tags          StringList `db:"tags" json:"tags"`
+	Format        string     `db:"format" json:"format"`
+	PostHistoryID *int64     `db:"post_history_id" json:"post_history_id"`
+	UserID        int64      `db:"user_id" json:"user_id"`
+	UpdateTime    int64      `db:"update_time" json:"update_time"`
+	InsertTime    int64      `db:"insert_time" json:"insert_time"`
+}
+
+// StringList is a list of strings stored as a JSON array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		msg := "cannot marshal string list: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return string(data), nil
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into string list", src)
+	}
+	err := json.Unmarshal(data, (*[]string)(l))
+	if err != nil {
+		msg := "cannot unmarshal string list: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// SaveWorkingCopy replaces the working copy of post with post. Like saving a
+// revision, it is refused with a ConflictError when the post has changed since
+// the revision post was edited from.
+func (database *Database) SaveWorkingCopy(post post.Post) (*WorkingCopy, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for SaveWorkingCopy: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	author, err := database.getUserByUsername(tx, post.Author())
+	if err != nil {
+		msg := "cannot get author in SaveWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	if author == nil {
+		msg := "no user '" + post.Author() + "' found in SaveWorkingCopy"
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	found, p, err := database.getPost(tx, post)
+	if err != nil {
+		msg := "cannot get post in SaveWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = database.checkBaseRevision(tx, post, found, p)
+	if err != nil {
+		rollback_err := tx.Rollback()
+		if rollback_err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + err.Error() + ": " + rollback_err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, err
+	}
+	err = database.upsertWorkingCopy(tx, post, author.ID)
+	if err != nil {
+		msg := "cannot save working copy in SaveWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	wc, err := database.getWorkingCopy(tx, post.UrlTitle())
+	if err != nil {
+		msg := "cannot get working copy in SaveWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in SaveWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in SaveWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return wc, nil
+}
+
+func (database *Database) GetWorkingCopy(url_title string) (*WorkingCopy, error) {
+	tx, err := database.db.Beginx()
+	if err != nil {
+		msg := "cannot begin transaction for GetWorkingCopy: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	wc, err := database.getWorkingCopy(tx, url_title)
+	if err != nil {
+		msg := "cannot get working copy in GetWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "cannot commit transaction in GetWorkingCopy: " + err.Error()
+		err = tx.Rollback()
+		if err != nil {
+			fatal := "cannot rollback in GetWorkingCopy: " + msg + ": " + err.Error()
+			return nil, errors.New(fatal)
+		}
+		return nil, errors.New(msg)
+	}
+	return wc, nil
+}
+
+func (database *Database) getWorkingCopy(tx *sqlx.Tx, url_title string) (*WorkingCopy, error) {
+	cols := `id, url_title, title, body, categories, tags, format, post_history_id, user_id, update_time, insert_time`
+	query := fmt.Sprintf(`SELECT %s FROM working_copy WHERE LOWER(url_title) = LOWER($1)`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for getWorkingCopy: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	defer stmt.Close()
+	row := stmt.QueryRowx(url_title)
+	var wc WorkingCopy
+	err = row.StructScan(&wc)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			msg := "cannot unmarshal working copy from getWorkingCopy: " + err.Error()
+			return nil, errors.New(msg)
+		}
+	}
+	return &wc, nil
+}
+
+// upsertWorkingCopy writes post over the working copy of the post, creating
+// it if there is none.
+func (database *Database) upsertWorkingCopy(tx *sqlx.Tx, post post.Post, user_id int64) error {
+	cols := `url_title, title, body, categories, tags, format, post_history_id, user_id`
+	query := fmt.Sprintf(`INSERT INTO working_copy (%s) VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT(url_title COLLATE NOCASE) DO UPDATE SET
+		url_title = excluded.url_title,
+		title = excluded.title,
+		body = excluded.body,
+		categories = excluded.categories,
+		tags = excluded.tags,
+		format = excluded.format,
+		post_history_id = excluded.post_history_id,
+		user_id = excluded.user_id,
+		update_time = (CAST(strftime('%%s', 'now') as integer))`, cols)
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for upsertWorkingCopy: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(post.UrlTitle(), post.Title, post.Body, StringList(post.Categories), StringList(post.Tags), post.Format, post.PostHistoryID, user_id)
+	if err != nil {
+		msg := "cannot execute query in upsertWorkingCopy: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// deleteWorkingCopy removes the working copy of a post, if it has one.
+func (database *Database) deleteWorkingCopy(tx *sqlx.Tx, url_title string) error {
+	query := `DELETE FROM working_copy WHERE LOWER(url_title) = LOWER($1)`
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		msg := "cannot prepare statement for deleteWorkingCopy: " + err.Error()
+		return errors.New(msg)
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(url_title)
+	if err != nil {
+		msg := "cannot execute query in deleteWorkingCopy: " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSaveWorkingCopy(t *testing.T) {
+	db := newTestDatabase(t)
+	p := testPost("Working Copy", "<p>first</p>")
+	first, err := db.SaveWorkingCopy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.UrlTitle != "Working-Copy" || first.Body != "<p>first</p>" || first.PostHistoryID != nil {
+		t.Errorf("saved working copy %+v", first)
+	}
+	p = testPost("WORKING Copy", "<p>second</p>")
+	p.Tags = nil
+	p.Categories = []string{"a", "b"}
+	p.Format = "markdown"
+	second, err := db.SaveWorkingCopy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("autosaving added working copy %d, want %d replaced", second.ID, first.ID)
+	}
+	got, err := db.GetWorkingCopy("Working-Copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatal("no working copy found")
+	}
+	if !reflect.DeepEqual(got, second) {
+		t.Errorf("got working copy %+v, want %+v", got, second)
+	}
+	if got.Title != "WORKING Copy" || got.Body != "<p>second</p>" || got.Format != "markdown" {
+		t.Errorf("working copy %+v was not replaced", got)
+	}
+	if !reflect.DeepEqual(got.Tags, StringList{}) || !reflect.DeepEqual(got.Categories, StringList{"a", "b"}) {
+		t.Errorf("working copy has tags %q and categories %q", got.Tags, got.Categories)
+	}
+	missing, err := db.GetWorkingCopy("missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Errorf("got working copy %+v of a post never autosaved", missing)
+	}
+}
+
+func TestSaveWorkingCopyConflicts(t *testing.T) {
+	db := newTestDatabase(t)
+	db_post := createTestPost(t, db, testPost("Working Copy", "<p>first</p>"))
+	first, err := db.GetLatestPostHistory(db_post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreatePost(testPost("Working Copy", "<p>second</p>"), DB_FALSE()); err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.GetLatestPostHistory(db_post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := testPost("Working Copy", "<p>stale</p>")
+	p.PostHistoryID = &first.ID
+	_, err = db.SaveWorkingCopy(p)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got error %v, want a ConflictError", err)
+	}
+	if conflict.Base != first.ID || conflict.Latest == nil || conflict.Latest.ID != second.ID {
+		t.Errorf("got conflict %+v, want revision %d to conflict with %d", conflict, first.ID, second.ID)
+	}
+	wc, err := db.GetWorkingCopy("working-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wc != nil {
+		t.Errorf("conflicting working copy %+v was saved", wc)
+	}
+	p.PostHistoryID = &second.ID
+	wc, err = db.SaveWorkingCopy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wc.PostHistoryID == nil || *wc.PostHistoryID != second.ID {
+		t.Errorf("working copy is edited from revision %v, want %d", wc.PostHistoryID, second.ID)
+	}
+}
+
+func TestWorkingCopyDeleted(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(t *testing.T, db *Database, db_post *Post)
+	}{
+		{
+			name: "saved revision",
+			remove: func(t *testing.T, db *Database, db_post *Post) {
+				if _, err := db.CreatePost(testPost("working copy", "<p>saved</p>"), DB_FALSE()); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "revised published post",
+			remove: func(t *testing.T, db *Database, db_post *Post) {
+				if err := db.PublishPost(db_post, "2020-1-1-working-copy.md"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := db.RevisePublishedPost(testPost("Working Copy", "<p>revised</p>")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "deleted post",
+			remove: func(t *testing.T, db *Database, db_post *Post) {
+				if err := db.DeletePost(db_post); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDatabase(t)
+			db_post := createTestPost(t, db, testPost("Working Copy", "<p>first</p>"))
+			if _, err := db.SaveWorkingCopy(testPost("Working Copy", "<p>autosaved</p>")); err != nil {
+				t.Fatal(err)
+			}
+			tt.remove(t, db, db_post)
+			wc, err := db.GetWorkingCopy("working-copy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wc != nil {
+				t.Errorf("working copy %+v was kept", wc)
+			}
+		})
+	}
+}
+
+func TestStringList(t *testing.T) {
+	tests := []struct {
+		list StringList
+		want string
+	}{
+		{nil, `[]`},
+		{StringList{}, `[]`},
+		{StringList{"a", `"b", c`}, `["a","\"b\", c"]`},
+	}
+	for _, tt := range tests {
+		value, err := tt.list.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != tt.want {
+			t.Errorf("%q is stored as %v, want %s", tt.list, value, tt.want)
+		}
+		var got StringList
+		if err := got.Scan([]byte(tt.want)); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.list) {
+			t.Errorf("%s is scanned as %q, want %q", tt.want, got, tt.list)
+		}
+		for i := range got {
+			if got[i] != tt.list[i] {
+				t.Errorf("%s is scanned as %q, want %q", tt.want, got, tt.list)
+			}
+		}
+	}
+	var list StringList
+	if err := list.Scan(int64(1)); err == nil {
+		t.Error("scanned an integer into a string list")
+	}
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// MethodImport is recorded as the method of imported revisions
+const MethodImport string = "IMPORT"
+
+// Config holds the values used for posts that leave them out.
+type Config struct {
+	Author   string
+	Category string
+	Tag      string
+}
+
+// Importer seeds the database with posts that were written outside of the
+// application.
+type Importer struct {
+	db  *database.Database
+	cfg Config
+}
+
+// Skipped is a file that could not be imported and the reason why.
+type Skipped struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+type Result struct {
+	Imported []string  `json:"imported"`
+	Skipped  []Skipped `json:"skipped"`
+}
+
+func New(cfg Config, db *database.Database) Importer {
+	return Importer{
+		db:  db,
+		cfg: cfg,
+	}
+}
@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Jekyll names posts YEAR-MONTH-DAY-title.MARKUP
+var jekyllName = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})-(.+)\.(md|markdown|html)$`)
+
+// Characters of Jekyll titles, tags and categories that post names cannot have
+var jekyllUnsafe = regexp.MustCompile(`[^a-zA-Z0-9-_ ]+`)
+
+// Date formats Jekyll accepts in the date front matter field
+var jekyllDates = []string{
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+type jekyllPost struct {
+	Title      string
+	Author     string
+	Categories []string
+	Tags       []string
+	Date       time.Time
+	Body       string
+	Format     string
+}
+
+// Jekyll imports every post in a Jekyll _posts directory as a posted post,
+// keeping its date and file name so that publishing it again replaces the
+// original file. Markdown bodies are converted to HTML like the editor writes
+// and published as Markdown again, HTML bodies are stored as they are. A file
+// that cannot be imported is skipped and reported in the result.
+func (i Importer) Jekyll(dir string) (*Result, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		msg := "cannot read posts directory " + dir + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	if !info.IsDir() {
+		msg := "posts path " + dir + " is not a directory"
+		return nil, errors.New(msg)
+	}
+	result := &Result{
+		Imported: []string{},
+		Skipped:  []Skipped{},
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !jekyllName.MatchString(info.Name()) {
+			result.Skipped = append(result.Skipped, Skipped{File: name, Reason: "not a Jekyll post file name"})
+			return nil
+		}
+		if err := i.importJekyllPost(path, name); err != nil {
+			result.Skipped = append(result.Skipped, Skipped{File: name, Reason: err.Error()})
+			return nil
+		}
+		result.Imported = append(result.Imported, name)
+		return nil
+	})
+	if err != nil {
+		msg := "cannot walk posts directory " + dir + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	return result, nil
+}
+
+func (i Importer) importJekyllPost(path string, name string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		msg := "cannot read post: " + err.Error()
+		return errors.New(msg)
+	}
+	jp, err := parseJekyllPost(filepath.Base(path), data)
+	if err != nil {
+		return err
+	}
+	author, err := i.author(jp.Author)
+	if err != nil {
+		return err
+	}
+	p := post.New(MethodImport, author)
+	p.Title = jp.Title
+	p.Body = jp.Body
+	p.Format = jp.Format
+	p.Categories = jp.Categories
+	if len(p.Categories) == 0 && i.cfg.Category != "" {
+		p.Categories = []string{i.cfg.Category}
+	}
+	p.Tags = jp.Tags
+	if len(p.Tags) == 0 && i.cfg.Tag != "" {
+		p.Tags = []string{i.cfg.Tag}
+	}
+	return i.db.ImportPost(p, jp.Date.Unix(), name)
+}
+
+// author returns the user name a post is imported as, falling back to the
+// configured author when the post has none or names an unknown user.
+func (i Importer) author(name string) (string, error) {
+	if name != "" {
+		db_user, err := i.db.GetUserByUsername(name)
+		if err != nil {
+			msg := "cannot get author '" + name + "': " + err.Error()
+			return "", errors.New(msg)
+		}
+		if db_user != nil {
+			return db_user.Username, nil
+		}
+	}
+	if i.cfg.Author == "" {
+		msg := "no user found for author '" + name + "'"
+		return "", errors.New(msg)
+	}
+	return i.cfg.Author, nil
+}
+
+func parseJekyllPost(filename string, data []byte) (*jekyllPost, error) {
+	m := jekyllName.FindStringSubmatch(filename)
+	if m == nil {
+		msg := "'" + filename + "' is not a Jekyll post file name"
+		return nil, errors.New(msg)
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	jp := &jekyllPost{
+		Title:  jekyllSafeName(strings.Replace(m[4], "-", " ", -1)),
+		Date:   time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC),
+		Format: processors.FORMAT_MARKDOWN,
+	}
+	front_matter, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+	jp.Body = strings.TrimLeft(string(body), "\r\n")
+	if m[5] == "html" {
+		jp.Format = processors.FORMAT_HTML
+	} else {
+		jp.Body = processors.MarkdownToHTML(jp.Body)
+	}
+	fm := make(map[string]interface{})
+	err = yaml.Unmarshal(front_matter, &fm)
+	if err != nil {
+		msg := "cannot parse front matter: " + err.Error()
+		return nil, errors.New(msg)
+	}
+	// Titles that cannot be post names fall back to the one in the file name
+	if title := fmString(fm["title"]); post.ValidName(title) {
+		jp.Title = title
+	}
+	jp.Author = fmString(fm["author"])
+	jp.Categories = jekyllSafeNames(append(fmList(fm["categories"]), fmList(fm["category"])...))
+	jp.Tags = jekyllSafeNames(append(fmList(fm["tags"]), fmList(fm["tag"])...))
+	if date := fmString(fm["date"]); date != "" {
+		t, err := parseJekyllDate(date)
+		if err != nil {
+			return nil, err
+		}
+		jp.Date = t
+	}
+	return jp, nil
+}
+
+// jekyllSafeName returns name with the characters post names cannot have
+// replaced by spaces, shortened to the length of post names when it is too
+// long.
+func jekyllSafeName(name string) string {
+	if post.ValidName(name) {
+		return name
+	}
+	name = strings.Join(strings.Fields(jekyllUnsafe.ReplaceAllString(name, " ")), " ")
+	if len(name) > 40 {
+		name = strings.TrimSpace(name[:40])
+	}
+	return name
+}
+
+// jekyllSafeNames returns the names of tags or categories that post names can
+// be made of, leaving out the ones that have nothing left.
+func jekyllSafeNames(names []string) []string {
+	safe_names := []string{}
+	for i := range names {
+		if name := jekyllSafeName(names[i]); name != "" {
+			safe_names = append(safe_names, name)
+		}
+	}
+	return safe_names
+}
+
+// splitFrontMatter separates the YAML between the leading --- lines of a post
+// from its content.
+func splitFrontMatter(data []byte) ([]byte, []byte, error) {
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	if !bytes.HasPrefix(data, []byte("---\n")) {
+		return nil, nil, errors.New("post has no front matter")
+	}
+	rest := data[len("---\n"):]
+	if bytes.HasPrefix(rest, []byte("---\n")) {
+		return []byte{}, rest[len("---\n"):], nil
+	}
+	end := bytes.Index(rest, []byte("\n---\n"))
+	if end < 0 {
+		if bytes.HasSuffix(rest, []byte("\n---")) {
+			return rest[:len(rest)-len("\n---")], []byte{}, nil
+		}
+		return nil, nil, errors.New("post front matter is not closed")
+	}
+	return rest[:end], rest[end+len("\n---\n"):], nil
+}
+
+func parseJekyllDate(date string) (time.Time, error) {
+	for _, layout := range jekyllDates {
+		t, err := time.Parse(layout, date)
+		if err == nil {
+			return t, nil
+		}
+	}
+	msg := "cannot parse date '" + date + "'"
+	return time.Time{}, errors.New(msg)
+}
+
+// fmString returns a scalar front matter value as a string. YAML timestamps
+// without quotes are decoded as strings by yaml.v2.
+func fmString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	switch s := v.(type) {
+	case string:
+		return strings.TrimSpace(s)
+	case time.Time:
+		return s.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+// fmList returns a front matter list, which Jekyll also allows to be written
+// as a single space separated string.
+func fmList(v interface{}) []string {
+	switch l := v.(type) {
+	case nil:
+		return []string{}
+	case []interface{}:
+		values := []string{}
+		for i := range l {
+			if s := fmString(l[i]); s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return strings.Fields(fmString(l))
+	}
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+func TestParseJekyllPost(t *testing.T) {
+	date := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		want     jekyllPost
+	}{
+		{
+			name:     "title from file name",
+			filename: "2020-01-02-hello-world.md",
+			data:     "---\n---\nHello",
+			want:     jekyllPost{Title: "hello world", Date: date, Body: "<p>Hello</p>", Format: processors.FORMAT_MARKDOWN, Categories: []string{}, Tags: []string{}},
+		},
+		{
+			name:     "front matter",
+			filename: "2020-1-2-hello-world.markdown",
+			data:     "---\ntitle: Hello World\nauthor: admin\ncategories: [one, two]\ncategory: three\ntags: a b\ntag: [c]\n---\n\n*Hello*\n",
+			want:     jekyllPost{Title: "Hello World", Author: "admin", Date: date, Body: "<p><em>Hello</em></p>", Format: processors.FORMAT_MARKDOWN, Categories: []string{"one", "two", "three"}, Tags: []string{"a", "b", "c"}},
+		},
+		{
+			name:     "title with punctuation",
+			filename: "2020-01-02-hello-world.md",
+			data:     "---\ntitle: \"Hello, World!\"\n---\n",
+			want:     jekyllPost{Title: "hello world", Date: date, Body: "", Format: processors.FORMAT_MARKDOWN, Categories: []string{}, Tags: []string{}},
+		},
+		{
+			name:     "long title",
+			filename: "2020-01-02-long.md",
+			data:     "---\ntitle: A title that is much longer than the forty characters allowed\n---\n",
+			want:     jekyllPost{Title: "long", Date: date, Body: "", Format: processors.FORMAT_MARKDOWN, Categories: []string{}, Tags: []string{}},
+		},
+		{
+			name:     "file name with punctuation",
+			filename: "2020-01-02-go-1.18:-generics.md",
+			data:     "---\ntitle: \"Go 1.18: generics\"\n---\n",
+			want:     jekyllPost{Title: "go 1 18 generics", Date: date, Body: "", Format: processors.FORMAT_MARKDOWN, Categories: []string{}, Tags: []string{}},
+		},
+		{
+			name:     "tags and categories with punctuation",
+			filename: "2020-01-02-names.md",
+			data:     "---\ncategories: [\"C++\", \"Go, Rust\", \"!!\"]\ntags: [node.js, a-tag-that-is-much-longer-than-forty-characters]\n---\n",
+			want:     jekyllPost{Title: "names", Date: date, Body: "", Format: processors.FORMAT_MARKDOWN, Categories: []string{"C", "Go Rust"}, Tags: []string{"node js", "a-tag-that-is-much-longer-than-forty-cha"}},
+		},
+		{
+			name:     "html",
+			filename: "2020-01-02-page.html",
+			data:     "---\ndate: 2020-03-04 05:06:07 +0100\n---\n<p>*Hello*</p>\n",
+			want:     jekyllPost{Title: "page", Date: time.Date(2020, time.March, 4, 5, 6, 7, 0, time.FixedZone("", 3600)), Body: "<p>*Hello*</p>\n", Format: processors.FORMAT_HTML, Categories: []string{}, Tags: []string{}},
+		},
+		{
+			name:     "unquoted date",
+			filename: "2020-01-02-dated.md",
+			data:     "---\ndate: 2020-03-04\n---\n",
+			want:     jekyllPost{Title: "dated", Date: time.Date(2020, time.March, 4, 0, 0, 0, 0, time.UTC), Body: "", Format: processors.FORMAT_MARKDOWN, Categories: []string{}, Tags: []string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJekyllPost(tt.filename, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Date.Equal(tt.want.Date) {
+				t.Errorf("got date %v, want %v", got.Date, tt.want.Date)
+			}
+			got.Date, tt.want.Date = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got post\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJekyllPostErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+	}{
+		{"file name", "hello-world.md", "---\n---\n"},
+		{"no front matter", "2020-01-02-hello.md", "Hello"},
+		{"invalid front matter", "2020-01-02-hello.md", "---\ntitle: [\n---\n"},
+		{"invalid date", "2020-01-02-hello.md", "---\ndate: yesterday\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseJekyllPost(tt.filename, []byte(tt.data)); err == nil {
+				t.Error("parsed an invalid post")
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		front_matter string
+		body         string
+	}{
+		{"front matter", "---\ntitle: a\n---\nbody\n", "title: a", "body\n"},
+		{"empty front matter", "---\n---\nbody", "", "body"},
+		{"windows line endings", "---\r\ntitle: a\r\n---\r\nbody\r\n", "title: a", "body\n"},
+		{"no body", "---\ntitle: a\n---", "title: a", ""},
+		{"rule in body", "---\ntitle: a\n---\none\n---\ntwo", "title: a", "one\n---\ntwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front_matter, body, err := splitFrontMatter([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(front_matter) != tt.front_matter || string(body) != tt.body {
+				t.Errorf("got front matter %q and body %q, want %q and %q", front_matter, body, tt.front_matter, tt.body)
+			}
+		})
+	}
+	for _, data := range []string{"title: a\n---\n", "---\ntitle: a\n"} {
+		if _, _, err := splitFrontMatter([]byte(data)); err == nil {
+			t.Errorf("split %q", data)
+		}
+	}
+}
+
+func TestParseJekyllDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want time.Time
+	}{
+		{"2020-03-04 05:06:07 -0500", time.Date(2020, time.March, 4, 10, 6, 7, 0, time.UTC)},
+		{"2020-03-04 05:06:07 +05:30", time.Date(2020, time.March, 3, 23, 36, 7, 0, time.UTC)},
+		{"2020-03-04 05:06:07", time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)},
+		{"2020-03-04 05:06 -0500", time.Date(2020, time.March, 4, 10, 6, 0, 0, time.UTC)},
+		{"2020-03-04 05:06", time.Date(2020, time.March, 4, 5, 6, 0, 0, time.UTC)},
+		{"2020-03-04T05:06:07Z", time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)},
+		{"2020-03-04", time.Date(2020, time.March, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseJekyllDate(tt.date)
+		if err != nil {
+			t.Errorf("cannot parse %q: %v", tt.date, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseJekyllDate(%q) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+	for _, date := range []string{"", "March 4 2020", "2020-13-01"} {
+		if _, err := parseJekyllDate(date); err == nil {
+			t.Errorf("parsed %q", date)
+		}
+	}
+}
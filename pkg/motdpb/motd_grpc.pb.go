@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: motd.proto
+
+package motdpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MotdService_SavePost_FullMethodName    = "/motdoftheday.v1.MotdService/SavePost"
+	MotdService_SubmitPost_FullMethodName  = "/motdoftheday.v1.MotdService/SubmitPost"
+	MotdService_ListDrafts_FullMethodName  = "/motdoftheday.v1.MotdService/ListDrafts"
+	MotdService_GetDraft_FullMethodName    = "/motdoftheday.v1.MotdService/GetDraft"
+	MotdService_GetRevision_FullMethodName = "/motdoftheday.v1.MotdService/GetRevision"
+	MotdService_Preview_FullMethodName     = "/motdoftheday.v1.MotdService/Preview"
+)
+
+// MotdServiceClient is the client API for MotdService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MotdService writes, previews and publishes posts like the web editor does.
+// Calls are authenticated with an API token sent as "authorization: Bearer
+// <token>" metadata, and fail with the gRPC code of their apierror status.
+type MotdServiceClient interface {
+	// SavePost stores a post as a new draft revision.
+	SavePost(ctx context.Context, in *SavePostRequest, opts ...grpc.CallOption) (*PostRevision, error)
+	// SubmitPost stores a post as a new revision and publishes it.
+	SubmitPost(ctx context.Context, in *SubmitPostRequest, opts ...grpc.CallOption) (*PostRevision, error)
+	// ListDrafts returns the posts that have not been published.
+	ListDrafts(ctx context.Context, in *ListDraftsRequest, opts ...grpc.CallOption) (*ListDraftsResponse, error)
+	// GetDraft returns a post along with every revision of it.
+	GetDraft(ctx context.Context, in *GetDraftRequest, opts ...grpc.CallOption) (*Draft, error)
+	// GetRevision returns one revision of a post.
+	GetRevision(ctx context.Context, in *GetRevisionRequest, opts ...grpc.CallOption) (*PostRevision, error)
+	// Preview renders a post as it would be published, without saving it.
+	Preview(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*PreviewResponse, error)
+}
+
+type motdServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMotdServiceClient(cc grpc.ClientConnInterface) MotdServiceClient {
+	return &motdServiceClient{cc}
+}
+
+func (c *motdServiceClient) SavePost(ctx context.Context, in *SavePostRequest, opts ...grpc.CallOption) (*PostRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostRevision)
+	err := c.cc.Invoke(ctx, MotdService_SavePost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motdServiceClient) SubmitPost(ctx context.Context, in *SubmitPostRequest, opts ...grpc.CallOption) (*PostRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostRevision)
+	err := c.cc.Invoke(ctx, MotdService_SubmitPost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motdServiceClient) ListDrafts(ctx context.Context, in *ListDraftsRequest, opts ...grpc.CallOption) (*ListDraftsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDraftsResponse)
+	err := c.cc.Invoke(ctx, MotdService_ListDrafts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motdServiceClient) GetDraft(ctx context.Context, in *GetDraftRequest, opts ...grpc.CallOption) (*Draft, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draft)
+	err := c.cc.Invoke(ctx, MotdService_GetDraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motdServiceClient) GetRevision(ctx context.Context, in *GetRevisionRequest, opts ...grpc.CallOption) (*PostRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostRevision)
+	err := c.cc.Invoke(ctx, MotdService_GetRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *motdServiceClient) Preview(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*PreviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreviewResponse)
+	err := c.cc.Invoke(ctx, MotdService_Preview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MotdServiceServer is the server API for MotdService service.
+// All implementations must embed UnimplementedMotdServiceServer
+// for forward compatibility.
+//
+// MotdService writes, previews and publishes posts like the web editor does.
+// Calls are authenticated with an API token sent as "authorization: Bearer
+// <token>" metadata, and fail with the gRPC code of their apierror status.
+type MotdServiceServer interface {
+	// SavePost stores a post as a new draft revision.
+	SavePost(context.Context, *SavePostRequest) (*PostRevision, error)
+	// SubmitPost stores a post as a new revision and publishes it.
+	SubmitPost(context.Context, *SubmitPostRequest) (*PostRevision, error)
+	// ListDrafts returns the posts that have not been published.
+	ListDrafts(context.Context, *ListDraftsRequest) (*ListDraftsResponse, error)
+	// GetDraft returns a post along with every revision of it.
+	GetDraft(context.Context, *GetDraftRequest) (*Draft, error)
+	// GetRevision returns one revision of a post.
+	GetRevision(context.Context, *GetRevisionRequest) (*PostRevision, error)
+	// Preview renders a post as it would be published, without saving it.
+	Preview(context.Context, *PreviewRequest) (*PreviewResponse, error)
+	mustEmbedUnimplementedMotdServiceServer()
+}
+
+// UnimplementedMotdServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMotdServiceServer struct{}
+
+func (UnimplementedMotdServiceServer) SavePost(context.Context, *SavePostRequest) (*PostRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SavePost not implemented")
+}
+func (UnimplementedMotdServiceServer) SubmitPost(context.Context, *SubmitPostRequest) (*PostRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitPost not implemented")
+}
+func (UnimplementedMotdServiceServer) ListDrafts(context.Context, *ListDraftsRequest) (*ListDraftsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDrafts not implemented")
+}
+func (UnimplementedMotdServiceServer) GetDraft(context.Context, *GetDraftRequest) (*Draft, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDraft not implemented")
+}
+func (UnimplementedMotdServiceServer) GetRevision(context.Context, *GetRevisionRequest) (*PostRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevision not implemented")
+}
+func (UnimplementedMotdServiceServer) Preview(context.Context, *PreviewRequest) (*PreviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Preview not implemented")
+}
+func (UnimplementedMotdServiceServer) mustEmbedUnimplementedMotdServiceServer() {}
+func (UnimplementedMotdServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeMotdServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MotdServiceServer will
+// result in compilation errors.
+type UnsafeMotdServiceServer interface {
+	mustEmbedUnimplementedMotdServiceServer()
+}
+
+func RegisterMotdServiceServer(s grpc.ServiceRegistrar, srv MotdServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMotdServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MotdService_ServiceDesc, srv)
+}
+
+func _MotdService_SavePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SavePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).SavePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_SavePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).SavePost(ctx, req.(*SavePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotdService_SubmitPost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitPostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).SubmitPost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_SubmitPost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).SubmitPost(ctx, req.(*SubmitPostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotdService_ListDrafts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDraftsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).ListDrafts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_ListDrafts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).ListDrafts(ctx, req.(*ListDraftsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotdService_GetDraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).GetDraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_GetDraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).GetDraft(ctx, req.(*GetDraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotdService_GetRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).GetRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_GetRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).GetRevision(ctx, req.(*GetRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MotdService_Preview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MotdServiceServer).Preview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MotdService_Preview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MotdServiceServer).Preview(ctx, req.(*PreviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MotdService_ServiceDesc is the grpc.ServiceDesc for MotdService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MotdService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "motdoftheday.v1.MotdService",
+	HandlerType: (*MotdServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SavePost",
+			Handler:    _MotdService_SavePost_Handler,
+		},
+		{
+			MethodName: "SubmitPost",
+			Handler:    _MotdService_SubmitPost_Handler,
+		},
+		{
+			MethodName: "ListDrafts",
+			Handler:    _MotdService_ListDrafts_Handler,
+		},
+		{
+			MethodName: "GetDraft",
+			Handler:    _MotdService_GetDraft_Handler,
+		},
+		{
+			MethodName: "GetRevision",
+			Handler:    _MotdService_GetRevision_Handler,
+		},
+		{
+			MethodName: "Preview",
+			Handler:    _MotdService_Preview_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "motd.proto",
+}
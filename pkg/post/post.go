@@ -5,22 +5,30 @@ import (
 	"regexp"
 	"strings"
 
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 type Post struct {
 	validator  *validator.Validate
 	method     string
+	author     string
 	Title      string   `json:"title" validate:"required"`
 	Tags       []string `json:"tags" validate:"required,min=1,max=10"`
 	Categories []string `json:"categories" validate:"required,min=1,max=10"`
 	Body       string   `json:"body" validate:"required"`
+	Format     string   `json:"format" validate:"omitempty,oneof=html markdown"`
+	// PostHistoryID is the revision the post was edited from, 0 for a new
+	// post. Saving is refused when the post has changed since, and is not
+	// checked when it is missing.
+	PostHistoryID *int64 `json:"post_history_id"`
 }
 
-func New(m string) Post {
+func New(m string, author string) Post {
 	return Post{
 		validator: validator.New(),
 		method:    m,
+		author:    author,
 	}
 }
 
@@ -28,6 +36,10 @@ func (p Post) Method() string {
 	return p.method
 }
 
+func (p Post) Author() string {
+	return p.author
+}
+
 func (p Post) UrlTitle() string {
 	return urlSafe(p.Title)
 }
@@ -58,31 +70,118 @@ func (p Post) UrlCategories() []string {
 	return categories
 }
 
+// ValidationError lists the fields of a post that are not valid.
+type ValidationError struct {
+	Fields []apierror.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := []string{}
+	for i := range e.Fields {
+		msgs = append(msgs, e.Fields[i].Field+" "+e.Fields[i].Message)
+	}
+	return "error validating post: " + strings.Join(msgs, ", ")
+}
+
+func (e *ValidationError) FieldErrors() []apierror.FieldError {
+	return e.Fields
+}
+
+// urlSafeName is what titles, tags and categories can be made of, so that
+// they can be used in urls and file names.
+var urlSafeName = regexp.MustCompile(`^[a-zA-Z0-9-_ ]{1,40}$`)
+
+const urlSafeMessage = "must be 1 to 40 letters, numbers, spaces, hyphens or underscores"
+
+// ValidName reports whether name can be used as a title, tag or category.
+func ValidName(name string) bool {
+	return urlSafeName.MatchString(name)
+}
+
+// Validate checks every field of the post, returning a ValidationError that
+// lists the fields that are not valid.
 func (p Post) Validate() error {
+	if p.author == "" {
+		return errors.New("post author is required")
+	}
+	fields := []apierror.FieldError{}
 	if err := p.validator.Struct(p); err != nil {
-		msg := "error validating post: " + err.Error()
-		return errors.New(msg)
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			msg := "error validating post: " + err.Error()
+			return errors.New(msg)
+		}
+		for i := range errs {
+			fields = append(fields, fieldError(errs[i]))
+		}
 	}
-	urlSafe := regexp.MustCompile(`^[a-zA-Z0-9-_ ]{1,40}$`)
-	if !urlSafe.MatchString(p.Title) {
-		msg := "post title '" + p.Title + "' not URL safe"
-		return errors.New(msg)
+	if p.Title != "" && !urlSafeName.MatchString(p.Title) {
+		fields = append(fields, apierror.FieldError{Field: "title", Message: urlSafeMessage})
 	}
 	for i := range p.Tags {
-		if !urlSafe.MatchString(p.Tags[i]) {
-			msg := "post tag '" + p.Tags[i] + "' not URL safe"
-			return errors.New(msg)
+		if !urlSafeName.MatchString(p.Tags[i]) {
+			fields = append(fields, apierror.FieldError{Field: "tags", Message: "'" + p.Tags[i] + "' " + urlSafeMessage})
 		}
 	}
 	for i := range p.Categories {
-		if !urlSafe.MatchString(p.Categories[i]) {
-			msg := "post category '" + p.Categories[i] + "' not URL safe"
+		if !urlSafeName.MatchString(p.Categories[i]) {
+			fields = append(fields, apierror.FieldError{Field: "categories", Message: "'" + p.Categories[i] + "' " + urlSafeMessage})
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ValidateWorkingCopy checks a post that is still being written, which only
+// needs the title it is found by.
+func (p Post) ValidateWorkingCopy() error {
+	if p.author == "" {
+		return errors.New("post author is required")
+	}
+	fields := []apierror.FieldError{}
+	if err := p.validator.StructPartial(p, "Format"); err != nil {
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			msg := "error validating post: " + err.Error()
 			return errors.New(msg)
 		}
+		for i := range errs {
+			fields = append(fields, fieldError(errs[i]))
+		}
+	}
+	if !urlSafeName.MatchString(p.Title) {
+		fields = append(fields, apierror.FieldError{Field: "title", Message: urlSafeMessage})
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
 	}
 	return nil
 }
 
+// fieldError describes a failed validate tag of a field by the json name
+// clients send it as.
+func fieldError(fe validator.FieldError) apierror.FieldError {
+	var msg string
+	switch fe.Tag() {
+	case "required":
+		msg = "is required"
+	case "min":
+		msg = "needs at least " + fe.Param()
+	case "max":
+		msg = "can have at most " + fe.Param()
+	case "oneof":
+		msg = "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	default:
+		msg = "is not valid"
+	}
+	return apierror.FieldError{
+		Field:   strings.ToLower(fe.Field()),
+		Message: msg,
+	}
+}
+
 func urlSafe(s string) string {
 	return strings.Join(strings.Split(strings.TrimSpace(s), " "), "-")
 }
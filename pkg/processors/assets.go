@@ -0,0 +1,168 @@
+package processors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// MAX_ASSET_SIZE is the largest file that can be uploaded as an asset
+const MAX_ASSET_SIZE int64 = 10 << 20
+
+// The editor references uploaded assets by their url on this server, which
+// is replaced by their url on the site when a post is rendered
+var (
+	assetSrc     = regexp.MustCompile(`(<img\b[^>]*?\ssrc\s*=\s*["'])/api/assets/([0-9]+)(?:/[^"'>]*)?(["'])`)
+	assetNameBad = regexp.MustCompile(`[^a-z0-9._-]+`)
+)
+
+// UploadAsset stores data uploaded as the file name by db_user. Uploading a
+// file that is already stored returns the existing asset.
+func (prcr Processor) UploadAsset(db_user *database.User, name string, data []byte, method string) (*database.Asset, apierror.IApiError) {
+	if db_user == nil {
+		msg := "no user found when uploading asset"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+		return nil, apiErr
+	}
+	if len(data) == 0 {
+		msg := "asset " + name + " is empty"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	if int64(len(data)) > MAX_ASSET_SIZE {
+		msg := "asset " + name + " is larger than " + strconv.FormatInt(MAX_ASSET_SIZE, 10) + " bytes"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	asset, err := prcr.db.CreateAsset(db_user, hash, assetName(name), http.DetectContentType(data), data)
+	if err != nil {
+		msg := "cannot store asset " + name + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return asset, nil
+}
+
+func (prcr Processor) GetAsset(asset_id int64, method string) (*database.Asset, apierror.IApiError) {
+	asset, err := prcr.db.GetAssetById(asset_id)
+	if err != nil {
+		msg := "error getting asset " + strconv.FormatInt(asset_id, 10) + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if asset == nil {
+		msg := "no asset found " + strconv.FormatInt(asset_id, 10)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return asset, nil
+}
+
+// EditorAssetURL is the url the editor shows asset at, which posts reference
+// it by until they are published.
+func EditorAssetURL(asset *database.Asset) string {
+	return "/api/assets/" + strconv.FormatInt(asset.ID, 10) + "/" + asset.Name
+}
+
+// assetFile is the file asset is published as. The hash keeps uploads of
+// different files with the same name apart.
+func assetFile(asset *database.Asset) string {
+	return asset.Hash[:16] + "-" + asset.Name
+}
+
+func (prcr Processor) assetURL(asset *database.Asset) string {
+	asset_url := prcr.cfg.AssetURL
+	if asset_url == "" {
+		asset_url = "/assets"
+	}
+	return strings.TrimSuffix(asset_url, "/") + "/" + assetFile(asset)
+}
+
+// rewriteAssets replaces the editor url of every asset in the images of body
+// with its url on the site, and returns the assets it references.
+func (prcr Processor) rewriteAssets(body string, method string) (string, []database.Asset, apierror.IApiError) {
+	assets := []database.Asset{}
+	found := make(map[int64]*database.Asset)
+	var apiErr apierror.IApiError
+	rewritten := assetSrc.ReplaceAllStringFunc(body, func(img string) string {
+		m := assetSrc.FindStringSubmatch(img)
+		asset_id, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil || apiErr != nil {
+			return img
+		}
+		asset, ok := found[asset_id]
+		if !ok {
+			asset, apiErr = prcr.GetAsset(asset_id, method)
+			if apiErr != nil {
+				return img
+			}
+			found[asset_id] = asset
+			assets = append(assets, *asset)
+		}
+		return m[1] + prcr.assetURL(asset) + m[3]
+	})
+	if apiErr != nil {
+		// A post referencing an asset that does not exist is a bad post
+		status := apiErr.Status()
+		if status == apierror.StatusNotFound {
+			status = apierror.StatusBadRequest
+		}
+		msg := "cannot find asset referenced by post: " + apiErr.Error()
+		apiErr := apierror.New(errors.New(msg), status, method)
+		return "", nil, apiErr
+	}
+	return rewritten, assets, nil
+}
+
+// publishAssets copies the assets referenced by the latest revision of a post
+// to the site.
+func (prcr Processor) publishAssets(db_post *database.Post, method string) apierror.IApiError {
+	latest_post, err := prcr.db.GetLatestPostHistory(db_post)
+	if err != nil {
+		msg := "error getting latest post " + db_post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	if latest_post == nil {
+		return nil
+	}
+	_, assets, apiErr := prcr.rewriteAssets(latest_post.Body, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	for i := range assets {
+		name := assetFile(&assets[i])
+		err := prcr.assets.Write(name, assets[i].Data, "Add asset "+name+" for \""+db_post.Title+"\"")
+		if err != nil {
+			msg := "cannot publish asset " + name + ": " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return apiErr
+		}
+	}
+	return nil
+}
+
+// assetName makes the name of an uploaded file safe to use in a url and as a
+// file name on the site.
+func assetName(name string) string {
+	name = strings.ToLower(path.Base(strings.Replace(name, "\\", "/", -1)))
+	ext := assetNameBad.ReplaceAllString(path.Ext(name), "")
+	if ext == "." {
+		ext = ""
+	}
+	stem := strings.Trim(assetNameBad.ReplaceAllString(strings.TrimSuffix(name, path.Ext(name)), "-"), "-.")
+	if stem == "" {
+		stem = "asset"
+	}
+	return stem + ext
+}
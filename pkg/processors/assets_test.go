@@ -0,0 +1,170 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// testPNG is the start of a PNG file, enough for its content type to be
+// detected.
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func testAdmin(t *testing.T, prcr Processor) *database.User {
+	t.Helper()
+	admin, err := prcr.db.GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admin
+}
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"cat.png", "cat.png"},
+		{"../../x.png", "x.png"},
+		{`a\b.PNG`, "b.png"},
+		{`C:\Users\me\cat.gif`, "cat.gif"},
+		{"/etc/passwd", "passwd"},
+		{".htaccess", "asset.htaccess"},
+		{".png", "asset.png"},
+		{"..", "asset"},
+		{"", "asset"},
+		{"My Photo (1).JPG", "my-photo-1.jpg"},
+		{"archive.", "archive"},
+		{"a.php%00.png", "a.php-00.png"},
+	}
+	for _, tt := range tests {
+		if got := assetName(tt.name); got != tt.want {
+			t.Errorf("assetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUploadAsset(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	admin := testAdmin(t, prcr)
+	asset, apiErr := prcr.UploadAsset(admin, "../My Cat.PNG", testPNG, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if asset.Name != "my-cat.png" || asset.ContentType != "image/png" || asset.Size != int64(len(testPNG)) || asset.UserID != admin.ID {
+		t.Errorf("uploaded asset %+v", asset)
+	}
+	if got := EditorAssetURL(asset); got != "/api/assets/"+strconv.FormatInt(asset.ID, 10)+"/my-cat.png" {
+		t.Errorf("editor url is %q", got)
+	}
+	again, apiErr := prcr.UploadAsset(admin, "other.png", testPNG, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if again.ID != asset.ID {
+		t.Errorf("uploading the same file again stored asset %d, want %d", again.ID, asset.ID)
+	}
+	tests := []struct {
+		name   string
+		user   *database.User
+		data   []byte
+		status apierror.Status
+	}{
+		{"no user", nil, testPNG, apierror.StatusUnauthorized},
+		{"empty", admin, []byte{}, apierror.StatusBadRequest},
+		{"too large", admin, make([]byte, MAX_ASSET_SIZE+1), apierror.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, apiErr := prcr.UploadAsset(tt.user, "asset.png", tt.data, apierror.MethodHTTP)
+			if apiErr == nil || apiErr.Status() != tt.status {
+				t.Errorf("got %v, want %s", apiErr, tt.status)
+			}
+		})
+	}
+	if _, apiErr := prcr.UploadAsset(admin, "largest.bin", make([]byte, MAX_ASSET_SIZE), apierror.MethodHTTP); apiErr != nil {
+		t.Errorf("cannot upload an asset of the largest size: %v", apiErr)
+	}
+}
+
+func TestRewriteAssets(t *testing.T) {
+	prcr := newTestProcessor(t, Config{AssetURL: "https://cdn.example.com/assets/"})
+	asset, apiErr := prcr.UploadAsset(testAdmin(t, prcr), "cat.png", testPNG, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	id := strconv.FormatInt(asset.ID, 10)
+	site_url := "https://cdn.example.com/assets/" + asset.Hash[:16] + "-cat.png"
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"double quotes", `<img src="/api/assets/` + id + `/cat.png">`, `<img src="` + site_url + `">`},
+		{"single quotes", `<img alt='a cat' src='/api/assets/` + id + `/cat.png'>`, `<img alt='a cat' src='` + site_url + `'>`},
+		{"without name", `<img src="/api/assets/` + id + `" alt="a cat">`, `<img src="` + site_url + `" alt="a cat">`},
+		{"spaces", `<img class="x" src = "/api/assets/` + id + `/other-name.png">`, `<img class="x" src = "` + site_url + `">`},
+		{"twice", `<img src="/api/assets/` + id + `/cat.png"><img src="/api/assets/` + id + `/cat.png">`, `<img src="` + site_url + `"><img src="` + site_url + `">`},
+		{"not an image", `<a href="/api/assets/` + id + `/cat.png">cat</a> /api/assets/` + id, `<a href="/api/assets/` + id + `/cat.png">cat</a> /api/assets/` + id},
+		{"other server", `<img src="https://example.com/api/assets/` + id + `/cat.png">`, `<img src="https://example.com/api/assets/` + id + `/cat.png">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, assets, apiErr := prcr.rewriteAssets(tt.body, apierror.MethodHTTP)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			if got != tt.want {
+				t.Errorf("rewriteAssets(%q) =\n%q\nwant\n%q", tt.body, got, tt.want)
+			}
+			if got != tt.body && (len(assets) != 1 || assets[0].ID != asset.ID) {
+				t.Errorf("got assets %+v, want the asset once", assets)
+			}
+		})
+	}
+	_, _, apiErr = prcr.rewriteAssets(`<img src="/api/assets/12345/missing.png">`, apierror.MethodHTTP)
+	if apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v for a missing asset, want %s", apiErr, apierror.StatusBadRequest)
+	}
+}
+
+func TestPublishAssets(t *testing.T) {
+	site := t.TempDir()
+	prcr := newTestProcessor(t, Config{Directory: filepath.Join(site, "_posts")})
+	asset, apiErr := prcr.UploadAsset(testAdmin(t, prcr), "cat.png", testPNG, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	body := `<p><img src="` + EditorAssetURL(asset) + `"></p>`
+	complete := createTestPost(t, prcr, "Cat Post", body)
+	published, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(site, "assets", assetFile(asset)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, testPNG) {
+		t.Errorf("published asset holds %q", data)
+	}
+	rendered, err := ioutil.ReadFile(filepath.Join(site, "_posts", published.Post.PublishedName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(rendered, []byte(`src="/assets/`+assetFile(asset)+`"`)) {
+		t.Errorf("published post does not reference the published asset:\n%s", rendered)
+	}
+
+	missing := createTestPost(t, prcr, "Missing Asset", `<p><img src="/api/assets/12345/missing.png"></p>`)
+	if _, apiErr := prcr.Publish(missing.Post.ID, apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v publishing a post with a missing asset, want %s", apiErr, apierror.StatusBadRequest)
+	}
+}
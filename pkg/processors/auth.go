@@ -0,0 +1,226 @@
+package processors
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength int = 8
+
+// Compared against when a login names an unknown user so that both failure
+// paths spend the same time in bcrypt.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("motdoftheday"), bcrypt.DefaultCost)
+
+func (prcr Processor) Login(username string, password string, ttl time.Duration, method string) (string, *database.User, apierror.IApiError) {
+	db_user, err := prcr.db.GetUserByUsername(username)
+	if err != nil {
+		msg := "error getting user in Login: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	if db_user == nil || db_user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		msg := "invalid user name or password"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+		return "", nil, apiErr
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(db_user.PasswordHash), []byte(password)); err != nil {
+		msg := "invalid user name or password"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+		return "", nil, apiErr
+	}
+	token, err := newToken()
+	if err != nil {
+		msg := "cannot create session token: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	err = prcr.db.CreateSession(db_user, hashToken(token), time.Now().Add(ttl).Unix())
+	if err != nil {
+		msg := "cannot create session: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	return token, db_user, nil
+}
+
+func (prcr Processor) Logout(token string, method string) apierror.IApiError {
+	err := prcr.db.DeleteSession(hashToken(token))
+	if err != nil {
+		msg := "cannot delete session: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+// Authenticate resolves a session token or an API token to its user.
+func (prcr Processor) Authenticate(token string, method string) (*database.User, apierror.IApiError) {
+	if token == "" {
+		msg := "no credentials provided"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+		return nil, apiErr
+	}
+	token_hash := hashToken(token)
+	var user_id int64
+	session, err := prcr.db.GetSessionByTokenHash(token_hash)
+	if err != nil {
+		msg := "error getting session: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if session != nil {
+		user_id = session.UserID
+	} else {
+		api_token, err := prcr.db.GetApiTokenByTokenHash(token_hash)
+		if err != nil {
+			msg := "error getting api token: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return nil, apiErr
+		}
+		if api_token == nil {
+			msg := "invalid or expired credentials"
+			apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+			return nil, apiErr
+		}
+		user_id = api_token.UserID
+	}
+	db_user, err := prcr.db.GetUserById(user_id)
+	if err != nil {
+		msg := "error getting authenticated user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if db_user == nil {
+		msg := "authenticated user no longer exists"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusUnauthorized, method)
+		return nil, apiErr
+	}
+	return db_user, nil
+}
+
+func (prcr Processor) SetPassword(user_id int64, password string, method string) apierror.IApiError {
+	if len(password) < minPasswordLength {
+		msg := "password must be at least 8 characters"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	db_user, apiErr := prcr.GetUser(user_id, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		msg := "cannot hash password: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	err = prcr.db.SetUserPassword(db_user, string(hash))
+	if err != nil {
+		msg := "cannot set password: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+// BootstrapPassword sets the password of a user that has never had one, so a
+// fresh install can be logged into. Without a password a random one is set,
+// which is returned so that it can be shown once. Nothing is returned when
+// the user already has a password.
+func (prcr Processor) BootstrapPassword(username string, password string, method string) (string, apierror.IApiError) {
+	db_user, apiErr := prcr.GetUserByUsername(username, method)
+	if apiErr != nil {
+		return "", apiErr
+	}
+	if db_user.PasswordHash != "" {
+		return "", nil
+	}
+	if password == "" {
+		token, err := newToken()
+		if err != nil {
+			msg := "cannot generate password: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return "", apiErr
+		}
+		password = token[:20]
+	}
+	apiErr = prcr.SetPassword(db_user.ID, password, method)
+	if apiErr != nil {
+		return "", apiErr
+	}
+	return password, nil
+}
+
+func (prcr Processor) ApiTokens(db_user *database.User, method string) ([]database.ApiToken, apierror.IApiError) {
+	tokens, err := prcr.db.GetUserApiTokens(db_user)
+	if err != nil {
+		msg := "cannot get api tokens: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return tokens, nil
+}
+
+// CreateApiToken returns the plain text token, which is only ever shown once.
+func (prcr Processor) CreateApiToken(db_user *database.User, name string, method string) (string, *database.ApiToken, apierror.IApiError) {
+	if name == "" {
+		msg := "api token name is required"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return "", nil, apiErr
+	}
+	token, err := newToken()
+	if err != nil {
+		msg := "cannot create api token: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	api_token, err := prcr.db.CreateApiToken(db_user, name, hashToken(token))
+	if err != nil {
+		msg := "cannot save api token: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return "", nil, apiErr
+	}
+	return token, api_token, nil
+}
+
+func (prcr Processor) DeleteApiToken(db_user *database.User, api_token_id int64, method string) apierror.IApiError {
+	api_token, err := prcr.db.GetApiTokenById(api_token_id)
+	if err != nil {
+		msg := "error getting api token: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	if api_token == nil || api_token.UserID != db_user.ID {
+		msg := "no api token exists with that id"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return apiErr
+	}
+	err = prcr.db.DeleteApiToken(api_token)
+	if err != nil {
+		msg := "cannot delete api token: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func TestBootstrapPassword(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	password, apiErr := prcr.BootstrapPassword("admin", "", apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(password) < minPasswordLength {
+		t.Fatalf("generated password %q is too short", password)
+	}
+	if _, _, apiErr := prcr.Login("admin", password, 0, apierror.MethodHTTP); apiErr != nil {
+		t.Fatalf("cannot log in with the generated password: %v", apiErr)
+	}
+	again, apiErr := prcr.BootstrapPassword("admin", "another password", apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if again != "" {
+		t.Errorf("bootstrapping a user with a password set it again to %q", again)
+	}
+	if _, _, apiErr := prcr.Login("admin", "another password", 0, apierror.MethodHTTP); apiErr == nil {
+		t.Error("bootstrapping a user with a password replaced it")
+	}
+}
@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// Autosave stores p as the working copy of the post, replacing the last one
+// so that autosaving does not add revisions. The working copy is replaced by
+// a revision when the post is saved or submitted. When the post has changed
+// since the revision p was edited from, the newer revision is returned along
+// with a CONFLICT error.
+func (prcr Processor) Autosave(p post.Post) (*database.WorkingCopy, *database.PostHistory, apierror.IApiError) {
+	err := p.ValidateWorkingCopy()
+	if err != nil {
+		apiErr := apierror.New(fmt.Errorf("invalid autosave post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, nil, apiErr
+	}
+	working_copy, err := prcr.db.SaveWorkingCopy(p)
+	if err != nil {
+		revision, apiErr := saveError("cannot autosave post: ", err, p.Method())
+		return nil, revision, apiErr
+	}
+	return working_copy, nil, nil
+}
+
+func (prcr Processor) GetWorkingCopy(url_title string, method string) (*database.WorkingCopy, apierror.IApiError) {
+	working_copy, err := prcr.db.GetWorkingCopy(url_title)
+	if err != nil {
+		msg := "error getting working copy of " + url_title + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if working_copy == nil {
+		msg := "no working copy found for " + url_title
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return working_copy, nil
+}
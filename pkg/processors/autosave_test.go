@@ -0,0 +1,55 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func TestAutosave(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	if _, apiErr := prcr.GetWorkingCopy("autosaved-post", apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusNotFound {
+		t.Errorf("got %v for a post never autosaved, want %s", apiErr, apierror.StatusNotFound)
+	}
+	// A working copy only needs a title
+	p := testPost("Autosaved Post", "")
+	p.Tags = nil
+	p.Categories = nil
+	if _, _, apiErr := prcr.Autosave(p); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	p.Body = "<p>autosaved</p>"
+	if _, _, apiErr := prcr.Autosave(p); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	working_copy, apiErr := prcr.GetWorkingCopy("autosaved-post", apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if working_copy.Body != "<p>autosaved</p>" {
+		t.Errorf("working copy body is %q", working_copy.Body)
+	}
+	complete := createTestPost(t, prcr, "Autosaved Post", "<p>saved</p>")
+	if _, apiErr := prcr.GetWorkingCopy("autosaved-post", apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusNotFound {
+		t.Errorf("got %v after saving the post, want %s", apiErr, apierror.StatusNotFound)
+	}
+	if len(complete.History) != 1 {
+		t.Errorf("post has %d revisions after autosaving twice, want 1", len(complete.History))
+	}
+
+	invalid := testPost("Autosaved/Post", "<p>autosaved</p>")
+	if _, _, apiErr := prcr.Autosave(invalid); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v for an invalid title, want %s", apiErr, apierror.StatusBadRequest)
+	}
+	stale := int64(0)
+	p.PostHistoryID = &stale
+	_, revision, apiErr := prcr.Autosave(p)
+	if apiErr == nil || apiErr.Status() != apierror.StatusConflict {
+		t.Fatalf("got %v for a stale working copy, want %s", apiErr, apierror.StatusConflict)
+	}
+	if revision == nil || revision.ID != complete.History[0].ID {
+		t.Errorf("got revision %+v along with the conflict, want %+v", revision, complete.History[0])
+	}
+}
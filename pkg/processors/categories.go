@@ -0,0 +1,18 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) Categories(method string) ([]database.Category, apierror.IApiError) {
+	categories, err := prcr.db.GetCategories()
+	if err != nil {
+		msg := "cannot get categories: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return categories, nil
+}
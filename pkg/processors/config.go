@@ -1,6 +1,70 @@
 package processors
 
+import (
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/publisher"
+)
+
+const (
+	PUBLISHER_FILE string = "file"
+	PUBLISHER_GIT  string = "git"
+	PUBLISHER_S3   string = "s3"
+)
+
+// Config selects where generated posts are written with Publisher. Without
+// one, posts are written to Directory, committed with git when it is enabled.
+// Posts are rendered with TemplateFile, or with the front matter of Flavor
+// when there is no template. The assets posts reference are published to
+// AssetDir, which the site serves at AssetURL.
 type Config struct {
-	Directory    string `yaml:"dir" validate:"required"`
-	TemplateFile string `yaml:"template" validate:"required"`
+	Directory    string              `yaml:"dir" validate:"required"`
+	TemplateFile string              `yaml:"template"`
+	Format       string              `yaml:"format" validate:"omitempty,oneof=html markdown"`
+	Flavor       FlavorConfig        `yaml:"flavor"`
+	AssetDir     string              `yaml:"asset_dir"`
+	AssetURL     string              `yaml:"asset_url"`
+	Publisher    string              `yaml:"publisher" validate:"omitempty,oneof=file git s3"`
+	Git          publisher.GitConfig `yaml:"git"`
+	S3           publisher.S3Config  `yaml:"s3"`
+}
+
+// newPublishers returns the configured publisher of posts, which writes to the
+// post directory of the site flavor unless another one is set, and of assets.
+// AssetDir is relative to the site, which for the file publisher is dir
+// without the flavor's post directory, or the parent of dir.
+func newPublishers(cfg Config) (publisher.Publisher, publisher.Publisher) {
+	if cfg.Git.PostDir == "" {
+		cfg.Git.PostDir = cfg.Flavor.PostDir()
+	}
+	if cfg.S3.Prefix == "" {
+		cfg.S3.Prefix = cfg.Flavor.PostDir()
+	}
+	asset_dir := cfg.AssetDir
+	if asset_dir == "" {
+		asset_dir = cfg.Flavor.AssetDir()
+	}
+	kind := cfg.Publisher
+	if kind == "" {
+		kind = PUBLISHER_FILE
+		if cfg.Git.Enabled {
+			kind = PUBLISHER_GIT
+		}
+	}
+	switch kind {
+	case PUBLISHER_GIT:
+		git := publisher.NewGit(cfg.Directory, cfg.Git)
+		return git, git.Dir(asset_dir)
+	case PUBLISHER_S3:
+		assets := cfg.S3
+		assets.Prefix = asset_dir
+		return publisher.NewS3(cfg.S3), publisher.NewS3(assets)
+	}
+	site := filepath.Dir(filepath.Clean(cfg.Directory))
+	post_dir := filepath.FromSlash(cfg.Flavor.PostDir())
+	if strings.HasSuffix(filepath.Clean(cfg.Directory), string(filepath.Separator)+post_dir) {
+		site = strings.TrimSuffix(filepath.Clean(cfg.Directory), string(filepath.Separator)+post_dir)
+	}
+	return publisher.NewFile(cfg.Directory), publisher.NewFile(filepath.Join(site, filepath.FromSlash(asset_dir)))
 }
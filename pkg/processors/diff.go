@@ -0,0 +1,341 @@
+package processors
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"golang.org/x/net/html"
+)
+
+const (
+	DIFF_EQUAL  string = "equal"
+	DIFF_INSERT string = "insert"
+	DIFF_DELETE string = "delete"
+)
+
+var diffToken = regexp.MustCompile(`\s+|[^\s]+`)
+
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	PostID            int64    `json:"post_id"`
+	From              int64    `json:"from"`
+	To                int64    `json:"to"`
+	Body              []DiffOp `json:"body"`
+	TagsAdded         []string `json:"tags_added"`
+	TagsRemoved       []string `json:"tags_removed"`
+	CategoriesAdded   []string `json:"categories_added"`
+	CategoriesRemoved []string `json:"categories_removed"`
+}
+
+// Diff compares two revisions of a post word by word. A from or to of 0
+// defaults to the revision before to and the latest revision respectively.
+func (prcr Processor) Diff(post_id int64, from int64, to int64, method string) (*RevisionDiff, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	complete_post, apiErr := prcr.completePost(db_post, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	history := complete_post.History
+	if len(history) == 0 {
+		msg := "no post history exists for Diff"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	to_index := len(history) - 1
+	if to != 0 {
+		to_index = historyIndex(history, to)
+		if to_index < 0 {
+			msg := "post history " + strconv.FormatInt(to, 10) + " does not belong to post " + strconv.FormatInt(post_id, 10)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+			return nil, apiErr
+		}
+	}
+	from_index := to_index - 1
+	if from != 0 {
+		from_index = historyIndex(history, from)
+		if from_index < 0 {
+			msg := "post history " + strconv.FormatInt(from, 10) + " does not belong to post " + strconv.FormatInt(post_id, 10)
+			apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+			return nil, apiErr
+		}
+	}
+	to_history := history[to_index]
+	// The first revision is compared against an empty post
+	from_history := database.PostHistory{PostID: post_id}
+	if from_index >= 0 {
+		from_history = history[from_index]
+	}
+	tags_added, tags_removed := diffNames(tagNames(complete_post.Tags[from_history.ID]), tagNames(complete_post.Tags[to_history.ID]))
+	categories_added, categories_removed := diffNames(categoryNames(complete_post.Categories[from_history.ID]), categoryNames(complete_post.Categories[to_history.ID]))
+	return &RevisionDiff{
+		PostID:            post_id,
+		From:              from_history.ID,
+		To:                to_history.ID,
+		Body:              diffWords(diffText(from_history.Body), diffText(to_history.Body)),
+		TagsAdded:         tags_added,
+		TagsRemoved:       tags_removed,
+		CategoriesAdded:   categories_added,
+		CategoriesRemoved: categories_removed,
+	}, nil
+}
+
+func historyIndex(history []database.PostHistory, post_history_id int64) int {
+	for i := range history {
+		if history[i].ID == post_history_id {
+			return i
+		}
+	}
+	return -1
+}
+
+func tagNames(tags []database.Tag) []string {
+	names := []string{}
+	for i := range tags {
+		names = append(names, tags[i].Name)
+	}
+	return names
+}
+
+func categoryNames(categories []database.Category) []string {
+	names := []string{}
+	for i := range categories {
+		names = append(names, categories[i].Name)
+	}
+	return names
+}
+
+func diffNames(from []string, to []string) ([]string, []string) {
+	in_from := make(map[string]bool)
+	for i := range from {
+		in_from[from[i]] = true
+	}
+	in_to := make(map[string]bool)
+	for i := range to {
+		in_to[to[i]] = true
+	}
+	added := []string{}
+	for i := range to {
+		if !in_from[to[i]] {
+			added = append(added, to[i])
+		}
+	}
+	removed := []string{}
+	for i := range from {
+		if !in_to[from[i]] {
+			removed = append(removed, from[i])
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// diffText returns the text of a post body with a line break after each
+// block element and list item, so that the diff reads like the rendered post.
+func diffText(body string) string {
+	z := html.NewTokenizer(strings.NewReader(body))
+	var sb strings.Builder
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return body
+			}
+			return strings.TrimSpace(mdClean(sb.String()))
+		case html.TextToken:
+			sb.WriteString(mdWhitespace.ReplaceAllString(string(z.Text()), " "))
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			block := mdBlockElements[string(name)] || string(name) == "li"
+			if string(name) == "br" || (tt == html.EndTagToken && block) {
+				sb.WriteString("\n")
+			}
+		}
+	}
+}
+
+// diffWords splits both texts into words and whitespace and returns the
+// shortest edit script between them, using the linear space variant of
+// Myers' algorithm. Texts with more than maxDiffTokens words and spaces
+// between them are too slow to compare, so everything between their common
+// start and end is shown as replaced.
+func diffWords(from string, to string) []DiffOp {
+	w := wordDiff{
+		a:   diffToken.FindAllString(from, -1),
+		b:   diffToken.FindAllString(to, -1),
+		ops: []DiffOp{},
+	}
+	n, m := len(w.a), len(w.b)
+	if n+m > maxDiffTokens {
+		w.replace(0, n, 0, m)
+	} else {
+		w.vf = make([]int, n+m+4)
+		w.vb = make([]int, n+m+4)
+		w.compare(0, n, 0, m)
+	}
+	w.flush()
+	return w.ops
+}
+
+const maxDiffTokens = 20000
+
+// wordDiff holds the words being compared and the edit script found so far,
+// along with the words of its last operation. vf and vb are the furthest
+// reaching paths of middleSnake, which are reused by every call to it.
+type wordDiff struct {
+	a    []string
+	b    []string
+	vf   []int
+	vb   []int
+	ops  []DiffOp
+	op   string
+	text strings.Builder
+}
+
+// add appends text to the edit script, merging it with the last operation
+// when that is the same.
+func (w *wordDiff) add(op string, text string) {
+	if op != w.op {
+		w.flush()
+		w.op = op
+	}
+	w.text.WriteString(text)
+}
+
+// flush ends the last operation of the edit script.
+func (w *wordDiff) flush() {
+	if w.text.Len() > 0 {
+		w.ops = append(w.ops, DiffOp{Op: w.op, Text: w.text.String()})
+	}
+	w.text.Reset()
+}
+
+// replace adds the words of a[a0:a1] and b[b0:b1] outside of their common
+// start and end as deleted and inserted.
+func (w *wordDiff) replace(a0 int, a1 int, b0 int, b1 int) {
+	for a0 < a1 && b0 < b1 && w.a[a0] == w.b[b0] {
+		w.add(DIFF_EQUAL, w.a[a0])
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && w.a[a1-suffix-1] == w.b[b1-suffix-1] {
+		suffix++
+	}
+	for i := a0; i < a1-suffix; i++ {
+		w.add(DIFF_DELETE, w.a[i])
+	}
+	for i := b0; i < b1-suffix; i++ {
+		w.add(DIFF_INSERT, w.b[i])
+	}
+	for i := a1 - suffix; i < a1; i++ {
+		w.add(DIFF_EQUAL, w.a[i])
+	}
+}
+
+// compare adds the edit script from a[a0:a1] to b[b0:b1]. Once their common
+// start and end are removed, the two differ by at least two edits, so
+// splitting them around the middle snake leaves two smaller problems.
+func (w *wordDiff) compare(a0 int, a1 int, b0 int, b1 int) {
+	for a0 < a1 && b0 < b1 && w.a[a0] == w.b[b0] {
+		w.add(DIFF_EQUAL, w.a[a0])
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && w.a[a1-suffix-1] == w.b[b1-suffix-1] {
+		suffix++
+	}
+	a1, b1 = a1-suffix, b1-suffix
+	switch {
+	case a0 == a1:
+		for i := b0; i < b1; i++ {
+			w.add(DIFF_INSERT, w.b[i])
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			w.add(DIFF_DELETE, w.a[i])
+		}
+	default:
+		x, y, u, v := w.middleSnake(a0, a1, b0, b1)
+		w.compare(a0, x, b0, y)
+		for i := x; i < u; i++ {
+			w.add(DIFF_EQUAL, w.a[i])
+		}
+		w.compare(u, a1, v, b1)
+	}
+	for i := a1; i < a1+suffix; i++ {
+		w.add(DIFF_EQUAL, w.a[i])
+	}
+}
+
+// middleSnake searches for the shortest edit script from a[a0:a1] to
+// b[b0:b1] from both ends at once and returns the snake where the two
+// searches meet, from (x, y) to (u, v). Paths searching backwards are kept
+// with both sequences reversed.
+func (w *wordDiff) middleSnake(a0 int, a1 int, b0 int, b1 int) (int, int, int, int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	w.vf[offset+1] = 0
+	w.vb[offset+1] = 0
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && w.vf[offset+k-1] < w.vf[offset+k+1]) {
+				x = w.vf[offset+k+1]
+			} else {
+				x = w.vf[offset+k-1] + 1
+			}
+			y := x - k
+			start_x, start_y := x, y
+			for x < n && y < m && w.a[a0+x] == w.b[b0+y] {
+				x++
+				y++
+			}
+			w.vf[offset+k] = x
+			// The backward path on the same diagonal is reversed
+			back_k := delta - k
+			if odd && back_k >= -(d-1) && back_k <= d-1 && x+w.vb[offset+back_k] >= n {
+				return a0 + start_x, b0 + start_y, a0 + x, b0 + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && w.vb[offset+k-1] < w.vb[offset+k+1]) {
+				x = w.vb[offset+k+1]
+			} else {
+				x = w.vb[offset+k-1] + 1
+			}
+			y := x - k
+			start_x, start_y := x, y
+			for x < n && y < m && w.a[a1-x-1] == w.b[b1-y-1] {
+				x++
+				y++
+			}
+			w.vb[offset+k] = x
+			forward_k := delta - k
+			if !odd && forward_k >= -d && forward_k <= d && x+w.vf[offset+forward_k] >= n {
+				return a1 - x, b1 - y, a1 - start_x, b1 - start_y
+			}
+		}
+	}
+	// Unreachable, the searches meet within max steps
+	return a0, b0, a0, b0
+}
@@ -0,0 +1,159 @@
+package processors
+
+import (
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []DiffOp
+	}{
+		{"empty", "", "", []DiffOp{}},
+		{"identical", "one two", "one two", []DiffOp{{DIFF_EQUAL, "one two"}}},
+		{"insert", "one three", "one two three", []DiffOp{{DIFF_EQUAL, "one "}, {DIFF_INSERT, "two "}, {DIFF_EQUAL, "three"}}},
+		{"insert into empty", "", "one two", []DiffOp{{DIFF_INSERT, "one two"}}},
+		{"delete", "one two three", "one three", []DiffOp{{DIFF_EQUAL, "one "}, {DIFF_DELETE, "two "}, {DIFF_EQUAL, "three"}}},
+		{"delete everything", "one two", "", []DiffOp{{DIFF_DELETE, "one two"}}},
+		{"replace", "one two three", "one 2 three", []DiffOp{{DIFF_EQUAL, "one "}, {DIFF_DELETE, "two"}, {DIFF_INSERT, "2"}, {DIFF_EQUAL, " three"}}},
+		{"replace everything", "a b", "c d", []DiffOp{{DIFF_DELETE, "a"}, {DIFF_INSERT, "c"}, {DIFF_EQUAL, " "}, {DIFF_DELETE, "b"}, {DIFF_INSERT, "d"}}},
+		{"whitespace", "one two", "one\ntwo", []DiffOp{{DIFF_EQUAL, "one"}, {DIFF_DELETE, " "}, {DIFF_INSERT, "\n"}, {DIFF_EQUAL, "two"}}},
+		{"moved word", "a b c", "b c a", []DiffOp{{DIFF_DELETE, "a "}, {DIFF_EQUAL, "b c"}, {DIFF_INSERT, " a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffWords(tt.from, tt.to)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffWords(%q, %q) =\n%v\nwant\n%v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLength is the number of words two texts have in common, found the slow
+// way.
+func lcsLength(a []string, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] > lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+// checkDiff checks that ops turns from into to and returns the number of
+// words they keep.
+func checkDiff(t *testing.T, from string, to string, ops []DiffOp) int {
+	t.Helper()
+	var got_from, got_to strings.Builder
+	equal := 0
+	for i := range ops {
+		if i > 0 && ops[i].Op == ops[i-1].Op {
+			t.Errorf("operations %d and %d are both %s", i-1, i, ops[i].Op)
+		}
+		switch ops[i].Op {
+		case DIFF_EQUAL:
+			got_from.WriteString(ops[i].Text)
+			got_to.WriteString(ops[i].Text)
+			equal += len(diffToken.FindAllString(ops[i].Text, -1))
+		case DIFF_DELETE:
+			got_from.WriteString(ops[i].Text)
+		case DIFF_INSERT:
+			got_to.WriteString(ops[i].Text)
+		}
+	}
+	if got_from.String() != from || got_to.String() != to {
+		t.Fatalf("diff of %q and %q is %v", from, to, ops)
+	}
+	return equal
+}
+
+func TestDiffWordsShortest(t *testing.T) {
+	words := []string{"a", "b", "c", " ", "\n"}
+	random := rand.New(rand.NewSource(1))
+	text := func() string {
+		var sb strings.Builder
+		for i := random.Intn(30); i > 0; i-- {
+			sb.WriteString(words[random.Intn(len(words))])
+		}
+		return sb.String()
+	}
+	for i := 0; i < 500; i++ {
+		from, to := text(), text()
+		equal := checkDiff(t, from, to, diffWords(from, to))
+		want := lcsLength(diffToken.FindAllString(from, -1), diffToken.FindAllString(to, -1))
+		if equal != want {
+			t.Errorf("diff of %q and %q keeps %d words, want %d", from, to, equal, want)
+		}
+	}
+}
+
+// testWords returns count different words starting with prefix.
+func testWords(prefix string, count int) string {
+	words := make([]string, count)
+	for i := range words {
+		words[i] = prefix + strconv.Itoa(i)
+	}
+	return strings.Join(words, " ")
+}
+
+func TestDiffWordsMemory(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"rewritten", testWords("a", 3000), testWords("b", 3000)},
+		{"edited", testWords("a", 3000), strings.Replace(testWords("a", 3000), "a1500", "b", 1)},
+		{"too long", testWords("a", 20000), testWords("b", 20000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			ops := diffWords(tt.from, tt.to)
+			runtime.ReadMemStats(&after)
+			// The words themselves take about 1 MB
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+				t.Errorf("diff allocated %d bytes", allocated)
+			}
+			checkDiff(t, tt.from, tt.to, ops)
+		})
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{``, ``},
+		{`<p>one</p><p>two</p>`, "one\ntwo"},
+		{`<p>one <b>two</b><br>three</p>`, "one two\nthree"},
+		{"<p>one \n\t two</p>", "one two"},
+		{`<ul><li>one</li><li>two</li></ul>`, "one\ntwo"},
+	}
+	for _, tt := range tests {
+		if got := diffText(tt.body); got != tt.want {
+			t.Errorf("diffText(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -11,28 +11,23 @@ func (prcr Processor) Draft(post_id int64, method string) (*database.CompletePos
 	db_post, err := prcr.db.GetPostById(post_id)
 	if err != nil {
 		msg := "error getting post in Draft: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", apierror.MethodHTTP)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return nil, apiErr
 	}
 	if db_post == nil {
 		msg := "No post exists for Draft"
-		apiErr := apierror.New(errors.New(msg), "NOT_FOUND", apierror.MethodHTTP)
-		return nil, apiErr
-	}
-	if db_post.Posted == database.DB_TRUE().Value() {
-		msg := "Post has already been posted for Draft"
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", apierror.MethodHTTP)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
 		return nil, apiErr
 	}
 	post, err := prcr.db.GetCompletePost(db_post)
 	if err != nil {
 		msg := "cannot get complete posts: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", method)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return nil, apiErr
 	}
 	if post == nil {
 		msg := "No complete post exists for Draft"
-		apiErr := apierror.New(errors.New(msg), "NOT_FOUND", apierror.MethodHTTP)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
 		return nil, apiErr
 	}
 	return post, nil
@@ -11,7 +11,7 @@ func (prcr Processor) Drafts(method string) ([]database.Post, apierror.IApiError
 	posts, err := prcr.db.GetDraftPosts()
 	if err != nil {
 		msg := "cannot get draft posts: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", method)
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return nil, apiErr
 	}
 	return posts, nil
@@ -0,0 +1,172 @@
+package processors
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// ARCHIVE_FILE is the file in an export that holds the database
+const ARCHIVE_FILE string = "motdoftheday.json"
+
+// Export writes a zip archive of the whole database to w. ARCHIVE_FILE holds
+// every user, post, revision, tag, category and asset, the latest revision of
+// each post is rendered with the post template under _posts or _drafts, and
+// assets are copied under assets. Each file is written to w as soon as it is
+// produced, so a post that cannot be rendered leaves the archive unfinished.
+// Nothing is written when the post template cannot be read.
+func (prcr Processor) Export(w io.Writer, method string) apierror.IApiError {
+	archive, err := prcr.db.ExportArchive()
+	if err != nil {
+		msg := "cannot export database: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	if _, apiErr := prcr.postTemplate(method); apiErr != nil {
+		apiErr := apierror.New(fmt.Errorf("cannot render posts for export: %w", apiErr), apiErr.Status(), method)
+		return apiErr
+	}
+	zw := zip.NewWriter(w)
+	f, apiErr := exportFile(zw, ARCHIVE_FILE, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(archive)
+	if err == nil {
+		err = zw.Flush()
+	}
+	if err != nil {
+		msg := "cannot write " + ARCHIVE_FILE + " to export: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	for i := range archive.Posts {
+		db_post := archive.Posts[i].Post
+		user, err := prcr.db.GetUserById(db_post.UserID)
+		if err != nil {
+			msg := "error getting user when exporting post: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return apiErr
+		}
+		if user == nil {
+			msg := "no user found when exporting post " + db_post.UrlTitle
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return apiErr
+		}
+		name, rendered, apiErr := prcr.renderPost(&db_post, user, method)
+		if apiErr != nil {
+			apiErr := apierror.New(fmt.Errorf("cannot render post %s for export: %w", db_post.UrlTitle, apiErr), apiErr.Status(), method)
+			return apiErr
+		}
+		dir := "_drafts/"
+		if db_post.Posted == database.DB_TRUE().Value() {
+			dir = "_posts/"
+		}
+		apiErr = writeExportFile(zw, dir+name, rendered, method)
+		if apiErr != nil {
+			return apiErr
+		}
+	}
+	for i := range archive.Assets {
+		apiErr := writeExportFile(zw, "assets/"+assetFile(&archive.Assets[i].Asset), archive.Assets[i].Data, method)
+		if apiErr != nil {
+			return apiErr
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		msg := "cannot finish export: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+// exportFile starts the file name in the export, which is written until the
+// next file is started.
+func exportFile(zw *zip.Writer, name string, method string) (io.Writer, apierror.IApiError) {
+	f, err := zw.Create(name)
+	if err != nil {
+		msg := "cannot add " + name + " to export: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return f, nil
+}
+
+// writeExportFile adds a file to the export and flushes it to the writer of
+// the export.
+func writeExportFile(zw *zip.Writer, name string, data []byte, method string) apierror.IApiError {
+	f, apiErr := exportFile(zw, name, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	_, err := f.Write(data)
+	if err == nil {
+		err = zw.Flush()
+	}
+	if err != nil {
+		msg := "cannot write " + name + " to export: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+// ImportArchive restores an archive written by Export into an empty
+// database. The rendered posts in the archive are not read, since they are
+// generated from the database.
+func (prcr Processor) ImportArchive(r io.ReaderAt, size int64, method string) apierror.IApiError {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		msg := "cannot read archive: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	var archive *database.Archive
+	for i := range zr.File {
+		if zr.File[i].Name != ARCHIVE_FILE {
+			continue
+		}
+		f, err := zr.File[i].Open()
+		if err != nil {
+			msg := "cannot open " + ARCHIVE_FILE + " in archive: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			return apiErr
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			msg := "cannot read " + ARCHIVE_FILE + " in archive: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			return apiErr
+		}
+		archive = &database.Archive{}
+		err = json.Unmarshal(data, archive)
+		if err != nil {
+			msg := "cannot unmarshal " + ARCHIVE_FILE + " in archive: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+			return apiErr
+		}
+	}
+	if archive == nil {
+		msg := "no " + ARCHIVE_FILE + " found in archive"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	err = prcr.db.ImportArchive(archive)
+	if err != nil {
+		msg := "cannot import archive: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	return nil
+}
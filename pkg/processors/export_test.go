@@ -0,0 +1,191 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+)
+
+// archiveZip returns a zip holding archive as ARCHIVE_FILE.
+func archiveZip(t *testing.T, archive *database.Archive) *bytes.Reader {
+	t.Helper()
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(ARCHIVE_FILE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestExportLeavesOutPasswords(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	u := user.New(apierror.MethodHTTP)
+	u.Username = "writer"
+	u.Firstname = "First"
+	u.Lastname = "Last"
+	writer, err := prcr.db.CreateUser(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiErr := prcr.SetPassword(writer.ID, "writer password", apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	createTestPost(t, prcr, "Exported Post", "<p>exported</p>")
+	var buf bytes.Buffer
+	if apiErr := prcr.Export(&buf, apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for i := range zr.File {
+		if zr.File[i].Name != ARCHIVE_FILE {
+			continue
+		}
+		f, err := zr.File[i].Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "password") || strings.Contains(string(data), "$2a$") {
+			t.Errorf("%s holds a password hash:\n%s", ARCHIVE_FILE, data)
+		}
+		found = true
+	}
+	if !found {
+		t.Fatalf("no %s in export", ARCHIVE_FILE)
+	}
+
+	imported := newTestProcessor(t, Config{})
+	if _, apiErr := imported.BootstrapPassword("admin", "admin password", apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if apiErr := imported.ImportArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()), apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	db_writer, err := imported.db.GetUserByUsername("writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db_writer == nil {
+		t.Fatal("writer was not imported")
+	}
+	if db_writer.PasswordHash != "" {
+		t.Error("imported user has a password")
+	}
+	if _, _, apiErr := imported.Login("admin", "admin password", 0, apierror.MethodHTTP); apiErr != nil {
+		t.Errorf("admin lost its password on import: %v", apiErr)
+	}
+	db_admin, err := imported.db.GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db_admin.IsAdmin() {
+		t.Error("admin is no longer an admin after import")
+	}
+	db_post, err := imported.db.GetPostByUrlTitle("exported-post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db_post == nil {
+		t.Fatal("post was not imported")
+	}
+}
+
+func TestImportArchiveRejectsInvalidArchives(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(archive *database.Archive)
+	}{
+		{name: "missing schema version", change: func(archive *database.Archive) { archive.SchemaVersion = 0 }},
+		{name: "newer schema version", change: func(archive *database.Archive) { archive.SchemaVersion = 9999 }},
+		{name: "parent directory", change: func(archive *database.Archive) { archive.Posts[0].PublishedName = "../../etc/cron.d/post.md" }},
+		{name: "dot dot", change: func(archive *database.Archive) { archive.Posts[0].PublishedName = "..md" }},
+		{name: "slash", change: func(archive *database.Archive) { archive.Posts[0].PublishedName = "drafts/post.md" }},
+		{name: "backslash", change: func(archive *database.Archive) { archive.Posts[0].PublishedName = `drafts\post.md` }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prcr := newTestProcessor(t, Config{})
+			createTestPost(t, prcr, "Archived Post", "<p>archived</p>")
+			archive, err := prcr.db.ExportArchive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.change(archive)
+			r := archiveZip(t, archive)
+			imported := newTestProcessor(t, Config{})
+			apiErr := imported.ImportArchive(r, r.Size(), apierror.MethodHTTP)
+			if apiErr == nil {
+				t.Fatal("archive was imported")
+			}
+			if apiErr.Status() != apierror.StatusBadRequest {
+				t.Errorf("status %s, expected %s", apiErr.Status(), apierror.StatusBadRequest)
+			}
+			db_post, err := imported.db.GetPostByUrlTitle("archived-post")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if db_post != nil {
+				t.Error("post of a rejected archive was imported")
+			}
+		})
+	}
+}
+
+func TestExportStreamsFiles(t *testing.T) {
+	template_file := filepath.Join(t.TempDir(), "post.tmpl")
+	tmpl := `{{ if eq .Post.Title "Broken Post" }}{{ .Missing }}{{ end }}{{ .LatestPost.Body }}`
+	if err := ioutil.WriteFile(template_file, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prcr := newTestProcessor(t, Config{TemplateFile: template_file})
+	createTestPost(t, prcr, "Exported Post", "<p>exported</p>")
+	createTestPost(t, prcr, "Broken Post", "<p>broken</p>")
+	var w bytes.Buffer
+	apiErr := prcr.Export(&w, apierror.MethodHTTP)
+	if apiErr == nil {
+		t.Fatal("exported a post that cannot be rendered")
+	}
+	// The files before the broken post were written before it was rendered
+	if !strings.Contains(w.String(), ARCHIVE_FILE) || !strings.Contains(w.String(), "_drafts/") {
+		t.Errorf("nothing was written before the broken post, got %d bytes", w.Len())
+	}
+
+	prcr = newTestProcessor(t, Config{TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")})
+	createTestPost(t, prcr, "Exported Post", "<p>exported</p>")
+	w.Reset()
+	if apiErr := prcr.Export(&w, apierror.MethodHTTP); apiErr == nil {
+		t.Fatal("exported without a post template")
+	}
+	if w.Len() != 0 {
+		t.Errorf("wrote %d bytes without a post template", w.Len())
+	}
+}
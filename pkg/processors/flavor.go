@@ -0,0 +1,269 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	FLAVOR_JEKYLL   string = "jekyll"
+	FLAVOR_HUGO     string = "hugo"
+	FLAVOR_ELEVENTY string = "eleventy"
+)
+
+const (
+	FRONT_MATTER_YAML string = "yaml"
+	FRONT_MATTER_TOML string = "toml"
+	FRONT_MATTER_JSON string = "json"
+)
+
+// FlavorConfig is the static site generator posts are published for. The
+// flavor decides the post directory and file names, and the front matter
+// written by the default template, in the flavor's usual format unless
+// FrontMatter is set. Fields adds fixed values to the front matter, such as a
+// layout or permalink, replacing those of the flavor.
+type FlavorConfig struct {
+	Name        string            `yaml:"name" validate:"omitempty,oneof=jekyll hugo eleventy"`
+	FrontMatter string            `yaml:"front_matter" validate:"omitempty,oneof=yaml toml json"`
+	Fields      map[string]string `yaml:"fields"`
+}
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type frontMatterField struct {
+	key   string
+	value interface{}
+}
+
+func (f FlavorConfig) name() string {
+	if f.Name == "" {
+		return FLAVOR_JEKYLL
+	}
+	return f.Name
+}
+
+// PostDir is the directory of the site that holds posts.
+func (f FlavorConfig) PostDir() string {
+	switch f.name() {
+	case FLAVOR_HUGO:
+		return "content/posts"
+	case FLAVOR_ELEVENTY:
+		return "posts"
+	}
+	return "_posts"
+}
+
+// AssetDir is the directory of the site that assets are copied to, which the
+// site serves as /assets.
+func (f FlavorConfig) AssetDir() string {
+	if f.name() == FLAVOR_HUGO {
+		return "static/assets"
+	}
+	return "assets"
+}
+
+// fileName returns the file of a post under the post directory. Jekyll posts
+// keep the unpadded date this tool has always written, Hugo posts are page
+// bundles so that assets can sit next to them, and Eleventy reads the date
+// from a padded prefix.
+func (f FlavorConfig) fileName(url_title string, post_time time.Time) string {
+	switch f.name() {
+	case FLAVOR_HUGO:
+		return url_title + "/index.md"
+	case FLAVOR_ELEVENTY:
+		return post_time.Format("2006-01-02") + "-" + url_title + ".md"
+	}
+	year, month, day := post_time.Date()
+	return strconv.Itoa(year) + "-" + strconv.Itoa(int(month)) + "-" + strconv.Itoa(day) + "-" + url_title + ".md"
+}
+
+func (f FlavorConfig) frontMatterFormat() string {
+	if f.FrontMatter != "" {
+		return f.FrontMatter
+	}
+	if f.name() == FLAVOR_HUGO {
+		return FRONT_MATTER_TOML
+	}
+	return FRONT_MATTER_YAML
+}
+
+// dateFormat is the layout of dates written as strings, which TOML does not
+// need since it has a date type.
+func (f FlavorConfig) dateFormat() string {
+	if f.name() == FLAVOR_JEKYLL {
+		return "2006-01-02 15:04:05 -0700"
+	}
+	return time.RFC3339
+}
+
+// fields returns the front matter of gp in the order it is written.
+func (f FlavorConfig) fields(gp generatedPost) []frontMatterField {
+	fields := []frontMatterField{}
+	if f.name() == FLAVOR_JEKYLL {
+		fields = append(fields, frontMatterField{"layout", "post"})
+	}
+	fields = append(fields,
+		frontMatterField{"title", gp.Post.Title},
+		frontMatterField{"date", gp.Date},
+	)
+	if f.name() == FLAVOR_HUGO {
+		fields = append(fields, frontMatterField{"lastmod", time.Unix(gp.LatestPost.InsertTime, 0).UTC()})
+	}
+	fields = append(fields, frontMatterField{"author", gp.User.Username})
+	categories := []string{}
+	for i := range gp.Categories {
+		categories = append(categories, gp.Categories[i].Name)
+	}
+	tags := []string{}
+	for i := range gp.Tags {
+		tags = append(tags, gp.Tags[i].Name)
+	}
+	fields = append(fields,
+		frontMatterField{"categories", categories},
+		frontMatterField{"tags", tags},
+	)
+	keys := []string{}
+	for k := range f.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		replaced := false
+		for i := range fields {
+			if fields[i].key == k {
+				fields[i].value = f.Fields[k]
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, frontMatterField{k, f.Fields[k]})
+		}
+	}
+	return fields
+}
+
+// frontMatter returns the front matter of gp along with its delimiters.
+func (f FlavorConfig) frontMatter(gp generatedPost) (string, error) {
+	fields := f.fields(gp)
+	switch f.frontMatterFormat() {
+	case FRONT_MATTER_TOML:
+		data, err := f.tomlFrontMatter(fields)
+		if err != nil {
+			return "", err
+		}
+		if f.name() == FLAVOR_HUGO {
+			return "+++\n" + data + "+++\n", nil
+		}
+		return "---toml\n" + data + "---\n", nil
+	case FRONT_MATTER_JSON:
+		data, err := f.jsonFrontMatter(fields)
+		if err != nil {
+			return "", err
+		}
+		if f.name() == FLAVOR_HUGO {
+			return data + "\n", nil
+		}
+		return "---json\n" + data + "\n---\n", nil
+	}
+	data, err := f.yamlFrontMatter(fields)
+	if err != nil {
+		return "", err
+	}
+	return "---\n" + data + "---\n", nil
+}
+
+func (f FlavorConfig) yamlFrontMatter(fields []frontMatterField) (string, error) {
+	ms := yaml.MapSlice{}
+	for i := range fields {
+		value := fields[i].value
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(f.dateFormat())
+		}
+		ms = append(ms, yaml.MapItem{Key: fields[i].key, Value: value})
+	}
+	data, err := yaml.Marshal(ms)
+	if err != nil {
+		msg := "cannot marshal yaml front matter: " + err.Error()
+		return "", errors.New(msg)
+	}
+	return string(data), nil
+}
+
+// jsonFrontMatter writes the fields as an object, keeping their order.
+func (f FlavorConfig) jsonFrontMatter(fields []frontMatterField) (string, error) {
+	lines := []string{}
+	for i := range fields {
+		value := fields[i].value
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(f.dateFormat())
+		}
+		key, err := jsonValue(fields[i].key)
+		if err != nil {
+			return "", err
+		}
+		data, err := jsonValue(value)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, "  "+key+": "+data)
+	}
+	return "{\n" + strings.Join(lines, ",\n") + "\n}", nil
+}
+
+func (f FlavorConfig) tomlFrontMatter(fields []frontMatterField) (string, error) {
+	var buf bytes.Buffer
+	for i := range fields {
+		key := fields[i].key
+		if !tomlBareKey.MatchString(key) {
+			quoted, err := jsonValue(key)
+			if err != nil {
+				return "", err
+			}
+			key = quoted
+		}
+		buf.WriteString(key + " = ")
+		switch v := fields[i].value.(type) {
+		case time.Time:
+			buf.WriteString(v.Format(time.RFC3339))
+		case []string:
+			values := []string{}
+			for j := range v {
+				value, err := jsonValue(v[j])
+				if err != nil {
+					return "", err
+				}
+				values = append(values, value)
+			}
+			buf.WriteString("[" + strings.Join(values, ", ") + "]")
+		default:
+			// TOML basic strings use the same escapes as JSON strings
+			value, err := jsonValue(v)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(value)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String(), nil
+}
+
+func jsonValue(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		msg := "cannot marshal front matter value: " + err.Error()
+		return "", errors.New(msg)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
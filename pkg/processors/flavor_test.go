@@ -0,0 +1,140 @@
+package processors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// escapedTitle has every character that needs escaping in one of the front
+// matter formats, along with the delimiters of each of them.
+const escapedTitle = "Say \"hi\" \\ 'there'\n---\n+++\t}: [# \u2028"
+
+func testGeneratedPost() generatedPost {
+	return generatedPost{
+		Post:       &database.Post{Title: escapedTitle},
+		User:       &database.User{Username: "admin"},
+		LatestPost: &database.PostHistory{InsertTime: time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC).Unix()},
+		Categories: []database.Category{{Name: `a "b"`}, {Name: "c, d"}},
+		Tags:       []database.Tag{{Name: `e\f`}, {Name: "g]h"}},
+		Date:       time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// frontMatterValues are the fields every flavor writes, decoded from YAML or
+// JSON front matter.
+type frontMatterValues struct {
+	Title      string   `yaml:"title" json:"title"`
+	Author     string   `yaml:"author" json:"author"`
+	Categories []string `yaml:"categories" json:"categories"`
+	Tags       []string `yaml:"tags" json:"tags"`
+	Extra      string   `yaml:"my key" json:"my key"`
+}
+
+func TestFrontMatterEscaping(t *testing.T) {
+	want := frontMatterValues{
+		Title:      escapedTitle,
+		Author:     "admin",
+		Categories: []string{`a "b"`, "c, d"},
+		Tags:       []string{`e\f`, "g]h"},
+		Extra:      escapedTitle,
+	}
+	tests := []struct {
+		name   string
+		flavor FlavorConfig
+		start  string
+		end    string
+		decode func(data []byte, v interface{}) error
+	}{
+		{"jekyll yaml", FlavorConfig{Name: FLAVOR_JEKYLL}, "---\n", "---\n", yaml.Unmarshal},
+		{"eleventy yaml", FlavorConfig{Name: FLAVOR_ELEVENTY}, "---\n", "---\n", yaml.Unmarshal},
+		{"hugo yaml", FlavorConfig{Name: FLAVOR_HUGO, FrontMatter: FRONT_MATTER_YAML}, "---\n", "---\n", yaml.Unmarshal},
+		{"jekyll json", FlavorConfig{Name: FLAVOR_JEKYLL, FrontMatter: FRONT_MATTER_JSON}, "---json\n", "\n---\n", json.Unmarshal},
+		{"eleventy json", FlavorConfig{Name: FLAVOR_ELEVENTY, FrontMatter: FRONT_MATTER_JSON}, "---json\n", "\n---\n", json.Unmarshal},
+		{"hugo json", FlavorConfig{Name: FLAVOR_HUGO, FrontMatter: FRONT_MATTER_JSON}, "{", "}\n", json.Unmarshal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.flavor.Fields = map[string]string{"my key": escapedTitle}
+			front_matter, err := tt.flavor.frontMatter(testGeneratedPost())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(front_matter, tt.start) || !strings.HasSuffix(front_matter, tt.end) {
+				t.Fatalf("front matter is not delimited by %q and %q:\n%s", tt.start, tt.end, front_matter)
+			}
+			data := strings.TrimSuffix(strings.TrimPrefix(front_matter, tt.start), tt.end)
+			for _, line := range strings.Split(data, "\n") {
+				if line == "---" || line == "+++" {
+					t.Fatalf("a value ends the front matter early:\n%s", front_matter)
+				}
+			}
+			if tt.start == "{" {
+				data = front_matter
+			}
+			var got frontMatterValues
+			if err := tt.decode([]byte(data), &got); err != nil {
+				t.Fatalf("cannot decode front matter: %v\n%s", err, front_matter)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decoded %+v, want %+v\n%s", got, want, front_matter)
+			}
+		})
+	}
+}
+
+// TestTOMLFrontMatterEscaping checks TOML front matter as written, since TOML
+// basic strings take the same escapes as the JSON strings written for them,
+// including the \u escape of line separators.
+func TestTOMLFrontMatterEscaping(t *testing.T) {
+	title := `"Say \"hi\" \\ 'there'\n---\n+++\t}: [# \u2028"`
+	tests := []struct {
+		name   string
+		flavor FlavorConfig
+		want   string
+	}{
+		{
+			name:   "hugo",
+			flavor: FlavorConfig{Name: FLAVOR_HUGO},
+			want: "+++\n" +
+				"title = " + title + "\n" +
+				"date = 2020-01-01T12:00:00Z\n" +
+				"lastmod = 2020-01-02T03:04:05Z\n" +
+				`author = "admin"` + "\n" +
+				`categories = ["a \"b\"", "c, d"]` + "\n" +
+				`tags = ["e\\f", "g]h"]` + "\n" +
+				`"my key" = ` + title + "\n" +
+				"+++\n",
+		},
+		{
+			name:   "jekyll",
+			flavor: FlavorConfig{Name: FLAVOR_JEKYLL, FrontMatter: FRONT_MATTER_TOML},
+			want: "---toml\n" +
+				`layout = "post"` + "\n" +
+				"title = " + title + "\n" +
+				"date = 2020-01-01T12:00:00Z\n" +
+				`author = "admin"` + "\n" +
+				`categories = ["a \"b\"", "c, d"]` + "\n" +
+				`tags = ["e\\f", "g]h"]` + "\n" +
+				`"my key" = ` + title + "\n" +
+				"---\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.flavor.Fields = map[string]string{"my key": escapedTitle}
+			got, err := tt.flavor.frontMatter(testGeneratedPost())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("front matter is\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,9 @@
 package processors
 
 import (
+	"bytes"
 	"errors"
-	"fmt"
 	"os"
-	"strconv"
 	"text/template"
 	"time"
 
@@ -13,116 +12,202 @@ import (
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
+// defaultTemplate renders posts when no template file is configured
+const defaultTemplate string = "{{ .FrontMatter }}{{ with .LatestPost }}{{ .Body }}{{ end }}"
+
 type generatedPost struct {
-	Post       *database.Post
-	User       *database.User
-	LatestPost *database.PostHistory
-	Categories []database.Category
-	Tags       []database.Tag
+	Post        *database.Post
+	User        *database.User
+	LatestPost  *database.PostHistory
+	Categories  []database.Category
+	Tags        []database.Tag
+	Date        time.Time
+	FrontMatter string
 }
 
 func (prcr Processor) generatePost(p post.Post) apierror.IApiError {
 	db_post, err := prcr.db.GetPostByUrlTitle(p.UrlTitle())
 	if err != nil {
 		msg := "error getting post " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
 		return apiErr
 	}
 	if db_post == nil {
-		msg := "no post found " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
+		msg := "no post found " + p.UrlTitle()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
 		return apiErr
 	}
+	return prcr.publishPost(db_post, p.Method())
+}
+
+// publishPost renders the latest revision of a post with the post template,
+// writes it to the site with the configured publisher and marks the post as
+// posted.
+func (prcr Processor) publishPost(db_post *database.Post, method string) apierror.IApiError {
 	user, err := prcr.db.GetUserById(db_post.UserID)
 	if err != nil {
 		msg := "error getting userwhen generating post: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return apiErr
 	}
 	if user == nil {
-		fmt.Println("tester")
 		msg := "no user found when generating post"
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
 		return apiErr
 	}
-	latest_post, err := prcr.db.GetLatestPostHistory(db_post)
-	if err != nil {
-		msg := "error getting latest post " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
+	name, data, apiErr := prcr.renderPost(db_post, user, method)
+	if apiErr != nil {
 		return apiErr
 	}
-	if latest_post == nil {
-		msg := "no post history found " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
+	apiErr = prcr.publishAssets(db_post, method)
+	if apiErr != nil {
 		return apiErr
 	}
-	categories, err := prcr.db.GetPostHistoryCategories(latest_post)
+	action := "Publish"
+	if db_post.PublishedName != "" && db_post.Posted == database.DB_TRUE().Value() {
+		action = "Update"
+	}
+	message := action + " \"" + db_post.Title + "\" by " + user.Firstname + " " + user.Lastname + " (" + user.Username + ")"
+	err = prcr.publisher.Write(name, data, message)
 	if err != nil {
-		msg := "error getting post categories " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
+		msg := "cannot publish post " + db_post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return apiErr
 	}
-	if len(categories) == 0 {
-		msg := "no categories for post " + p.UrlTitle()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
+	err = prcr.db.PublishPost(db_post, name)
+	if err != nil {
+		msg := "cannot mark post " + db_post.UrlTitle + " as posted: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
 		return apiErr
 	}
-	tags, err := prcr.db.GetPostHistoryTags(latest_post)
+	return nil
+}
+
+// renderPost renders the latest revision of a post written by user and
+// returns it along with the file name it is published under.
+func (prcr Processor) renderPost(db_post *database.Post, user *database.User, method string) (string, []byte, apierror.IApiError) {
+	latest_post, err := prcr.db.GetLatestPostHistory(db_post)
 	if err != nil {
-		msg := "error getting post tags " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-		return apiErr
+		msg := "error getting latest post " + db_post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
 	}
-	if len(tags) == 0 {
-		msg := "no tags for post " + p.UrlTitle()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+	if latest_post == nil {
+		msg := "no post history found " + db_post.UrlTitle
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return "", nil, apiErr
 	}
-	if db_post == nil {
-		msg := "Post does not exists " + p.UrlTitle() + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+	categories, err := prcr.db.GetPostHistoryCategories(latest_post)
+	if err != nil {
+		msg := "error getting post categories " + db_post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
 	}
-	if _, err := os.Stat(prcr.cfg.TemplateFile); err != nil {
-		msg := "Template file " + prcr.cfg.TemplateFile + " does not exist: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-		return apiErr
+	if len(categories) == 0 {
+		msg := "no categories for post " + db_post.UrlTitle
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return "", nil, apiErr
 	}
-	tmpl, err := template.ParseFiles(prcr.cfg.TemplateFile)
+	tags, err := prcr.db.GetPostHistoryTags(latest_post)
 	if err != nil {
-		msg := "Cannot read template file " + prcr.cfg.TemplateFile + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-		return apiErr
+		msg := "error getting post tags " + db_post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
 	}
-	year, month, day := time.Unix(latest_post.InsertTime, 0).UTC().Date()
-	if _, err := os.Stat(prcr.cfg.Directory); os.IsNotExist(err) {
-		e := os.MkdirAll(prcr.cfg.Directory, os.ModePerm)
-		if e != nil {
-			msg := "cannot create post dir " + prcr.cfg.Directory + ": " + e.Error()
-			apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-			return apiErr
-		}
+	if len(tags) == 0 {
+		msg := "no tags for post " + db_post.UrlTitle
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return "", nil, apiErr
+	}
+	post_time := latest_post.InsertTime
+	if db_post.PublishAt != nil {
+		post_time = *db_post.PublishAt
 	}
-	filename := prcr.cfg.Directory + "/" + strconv.Itoa(year) + "-" + strconv.Itoa(int(month)) + "-" + strconv.Itoa(day) + "-" + db_post.UrlTitle + ".md"
-	gp := generatedPost{
+	name := prcr.postName(db_post, post_time)
+	data, apiErr := prcr.renderTemplate(generatedPost{
 		Post:       db_post,
 		User:       user,
 		LatestPost: latest_post,
 		Categories: categories,
 		Tags:       tags,
+		Date:       time.Unix(post_time, 0).UTC(),
+	}, method)
+	if apiErr != nil {
+		return "", nil, apiErr
+	}
+	return name, data, nil
+}
+
+// postName returns the file a post is published as under the post directory
+// of the site flavor. Posts that were published before keep their file, and
+// with it their date and permalink.
+func (prcr Processor) postName(db_post *database.Post, post_time int64) string {
+	if db_post.PublishedName != "" {
+		return db_post.PublishedName
+	}
+	return prcr.cfg.Flavor.fileName(db_post.UrlTitle, time.Unix(post_time, 0).UTC())
+}
+
+// renderTemplate executes the post template, or the default template that
+// writes the front matter of the site flavor followed by the body. Images of
+// uploaded assets are pointed at the site first, then the body is converted to
+// markdown when that is the format of the post.
+func (prcr Processor) renderTemplate(gp generatedPost, method string) ([]byte, apierror.IApiError) {
+	tmpl, apiErr := prcr.postTemplate(method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	body, _, apiErr := prcr.rewriteAssets(gp.LatestPost.Body, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	format := gp.Post.Format
+	if format == "" {
+		format = prcr.cfg.Format
+	}
+	if format == FORMAT_MARKDOWN {
+		markdown, err := htmlToMarkdown(body)
+		if err != nil {
+			msg := "cannot convert post " + gp.Post.UrlTitle + " to markdown: " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return nil, apiErr
+		}
+		body = markdown
 	}
-	f, err := os.Create(filename)
+	rendered_post := *gp.LatestPost
+	rendered_post.Body = body
+	gp.LatestPost = &rendered_post
+	front_matter, err := prcr.cfg.Flavor.frontMatter(gp)
 	if err != nil {
-		msg := "cannot open post file" + filename + ": " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-		return apiErr
+		msg := "cannot write front matter of post " + gp.Post.UrlTitle + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
 	}
-	defer f.Close()
-	err = tmpl.Execute(f, gp)
+	gp.FrontMatter = front_matter
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, gp)
 	if err != nil {
 		msg := "Cannot render template: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "INTERNAL", p.Method())
-		return apiErr
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
 	}
-	return nil
+	return buf.Bytes(), nil
+}
+
+func (prcr Processor) postTemplate(method string) (*template.Template, apierror.IApiError) {
+	if prcr.cfg.TemplateFile == "" {
+		return template.Must(template.New("post").Parse(defaultTemplate)), nil
+	}
+	if _, err := os.Stat(prcr.cfg.TemplateFile); err != nil {
+		msg := "Template file " + prcr.cfg.TemplateFile + " does not exist: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	tmpl, err := template.ParseFiles(prcr.cfg.TemplateFile)
+	if err != nil {
+		msg := "Cannot read template file " + prcr.cfg.TemplateFile + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return tmpl, nil
 }
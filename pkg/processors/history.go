@@ -0,0 +1,22 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) History(post_id int64, method string) ([]database.PostHistory, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	history, err := prcr.db.GetPostHistory(db_post)
+	if err != nil {
+		msg := "cannot get post history: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return history, nil
+}
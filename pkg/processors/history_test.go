@@ -0,0 +1,113 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func TestDiff(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	first := createTestPost(t, prcr, "Diffed Post", "<p>one two</p>")
+	p := testPost("Diffed Post", "<p>one three</p><p>four</p>")
+	p.Tags = []string{"test", "new"}
+	p.Categories = []string{"other"}
+	second, apiErr := prcr.UpdatePost(first.Post.ID, p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	first_id, second_id := first.History[0].ID, second.History[1].ID
+	tests := []struct {
+		name string
+		from int64
+		to   int64
+		want RevisionDiff
+	}{
+		{
+			name: "latest revision",
+			want: RevisionDiff{
+				From:              first_id,
+				To:                second_id,
+				Body:              []DiffOp{{DIFF_EQUAL, "one "}, {DIFF_DELETE, "two"}, {DIFF_INSERT, "three\nfour"}},
+				TagsAdded:         []string{"new"},
+				TagsRemoved:       []string{},
+				CategoriesAdded:   []string{"other"},
+				CategoriesRemoved: []string{"testing"},
+			},
+		},
+		{
+			name: "first revision",
+			to:   first_id,
+			want: RevisionDiff{
+				To:                first_id,
+				Body:              []DiffOp{{DIFF_INSERT, "one two"}},
+				TagsAdded:         []string{"test"},
+				TagsRemoved:       []string{},
+				CategoriesAdded:   []string{"testing"},
+				CategoriesRemoved: []string{},
+			},
+		},
+		{
+			name: "backwards",
+			from: second_id,
+			to:   first_id,
+			want: RevisionDiff{
+				From:              second_id,
+				To:                first_id,
+				Body:              []DiffOp{{DIFF_EQUAL, "one "}, {DIFF_DELETE, "three\nfour"}, {DIFF_INSERT, "two"}},
+				TagsAdded:         []string{},
+				TagsRemoved:       []string{"new"},
+				CategoriesAdded:   []string{"testing"},
+				CategoriesRemoved: []string{"other"},
+			},
+		},
+		{
+			name: "same revision",
+			from: first_id,
+			to:   first_id,
+			want: RevisionDiff{
+				From:              first_id,
+				To:                first_id,
+				Body:              []DiffOp{{DIFF_EQUAL, "one two"}},
+				TagsAdded:         []string{},
+				TagsRemoved:       []string{},
+				CategoriesAdded:   []string{},
+				CategoriesRemoved: []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, apiErr := prcr.Diff(first.Post.ID, tt.from, tt.to, apierror.MethodHTTP)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			tt.want.PostID = first.Post.ID
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got diff\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+	other := createTestPost(t, prcr, "Other Post", "<p>other</p>")
+	missing := []struct {
+		name    string
+		post_id int64
+		from    int64
+		to      int64
+	}{
+		{"missing post", 12345, 0, 0},
+		{"revision of another post", first.Post.ID, 0, other.History[0].ID},
+		{"missing revision", first.Post.ID, 12345, 0},
+	}
+	for _, tt := range missing {
+		t.Run(tt.name, func(t *testing.T) {
+			_, apiErr := prcr.Diff(tt.post_id, tt.from, tt.to, apierror.MethodHTTP)
+			if apiErr == nil || apiErr.Status() != apierror.StatusNotFound {
+				t.Errorf("got %v, want %s", apiErr, apierror.StatusNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package processors
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	FORMAT_HTML     string = "html"
+	FORMAT_MARKDOWN string = "markdown"
+)
+
+// The editor runs with styleWithCSS turned on, so most formatting arrives as
+// spans with inline styles rather than as <b> or <i> elements.
+var (
+	mdBoldStyle      = regexp.MustCompile(`font-weight:\s*(bold|bolder|[6-9]00)`)
+	mdItalicStyle    = regexp.MustCompile(`font-style:\s*(italic|oblique)`)
+	mdUnderlineStyle = regexp.MustCompile(`text-decoration[a-z-]*:[^;]*underline`)
+	mdStrikeStyle    = regexp.MustCompile(`text-decoration[a-z-]*:[^;]*line-through`)
+	mdSubStyle       = regexp.MustCompile(`vertical-align:\s*sub`)
+	mdSuperStyle     = regexp.MustCompile(`vertical-align:\s*super`)
+	mdWhitespace     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	mdLineStart      = regexp.MustCompile(`^([#>+=-]|[0-9]+[.)])`)
+)
+
+var mdBlockElements = map[string]bool{
+	"address":    true,
+	"article":    true,
+	"aside":      true,
+	"blockquote": true,
+	"center":     true,
+	"div":        true,
+	"dl":         true,
+	"figure":     true,
+	"footer":     true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"header":     true,
+	"hr":         true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"section":    true,
+	"table":      true,
+	"ul":         true,
+}
+
+type mdBlock struct {
+	text string
+	list bool
+}
+
+type mdStyle struct {
+	bold   bool
+	italic bool
+}
+
+// htmlToMarkdown converts the HTML produced by the editor into Markdown that
+// both Kramdown and CommonMark render the same way. Formatting Markdown has
+// no syntax for, such as underline, strikethrough and sub/superscript, is
+// kept as inline HTML.
+func htmlToMarkdown(body string) (string, error) {
+	context := &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(body), context)
+	if err != nil {
+		msg := "cannot parse post body html: " + err.Error()
+		return "", errors.New(msg)
+	}
+	md := mdJoin(mdBlocks(nodes))
+	if md == "" {
+		return "", nil
+	}
+	return md + "\n", nil
+}
+
+func mdBlocks(nodes []*html.Node) []mdBlock {
+	blocks := []mdBlock{}
+	inline := []*html.Node{}
+	flush := func() {
+		for _, p := range mdParagraphs(mdInline(inline, mdStyle{})) {
+			blocks = append(blocks, mdBlock{text: p})
+		}
+		inline = []*html.Node{}
+	}
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && mdBlockElements[n.Data] {
+			flush()
+			blocks = append(blocks, mdBlockNode(n)...)
+			continue
+		}
+		inline = append(inline, n)
+	}
+	flush()
+	return blocks
+}
+
+func mdBlockNode(n *html.Node) []mdBlock {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Data[1:])
+		text := strings.Join(mdParagraphs(mdInline(mdChildren(n), mdStyle{})), " ")
+		text = strings.Replace(text, "  \n", " ", -1)
+		if text == "" {
+			return nil
+		}
+		return []mdBlock{{text: strings.Repeat("#", level) + " " + text}}
+	case "ul", "ol":
+		text := mdList(n)
+		if text == "" {
+			return nil
+		}
+		return []mdBlock{{text: text, list: true}}
+	case "pre":
+		return []mdBlock{{text: mdCodeBlock(n)}}
+	case "blockquote":
+		text := mdJoin(mdBlocks(mdChildren(n)))
+		if text == "" {
+			return nil
+		}
+		return []mdBlock{{text: mdPrefixLines(text, "> ", ">")}}
+	case "hr":
+		return []mdBlock{{text: "* * *"}}
+	case "table", "dl", "figure":
+		var buf bytes.Buffer
+		if err := html.Render(&buf, n); err != nil {
+			return nil
+		}
+		return []mdBlock{{text: buf.String()}}
+	}
+	return mdBlocks(mdChildren(n))
+}
+
+func mdInline(nodes []*html.Node, style mdStyle) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		sb.WriteString(mdInlineNode(n, style))
+	}
+	return sb.String()
+}
+
+func mdInlineNode(n *html.Node, style mdStyle) string {
+	switch n.Type {
+	case html.TextNode:
+		return mdEscape(mdCollapse(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "script", "style", "template", "title", "head":
+		return ""
+	case "img":
+		src := mdAttr(n, "src")
+		if src == "" {
+			return ""
+		}
+		alt := mdEscape(mdCollapse(mdAttr(n, "alt")))
+		return "![" + alt + "](" + mdDestination(src) + mdTitle(mdAttr(n, "title")) + ")"
+	case "code", "kbd", "samp", "tt":
+		return mdCodeSpan(mdCollapse(mdText(n)))
+	case "a":
+		inner := mdStyled(n, style)
+		href := mdAttr(n, "href")
+		if href == "" || strings.TrimSpace(inner) == "" {
+			return inner
+		}
+		return "[" + inner + "](" + mdDestination(href) + mdTitle(mdAttr(n, "title")) + ")"
+	}
+	if mdBlockElements[n.Data] {
+		return "\n" + mdStyled(n, style) + "\n"
+	}
+	return mdStyled(n, style)
+}
+
+// mdStyled renders the children of n, wrapping them in whatever emphasis or
+// inline HTML the element and its style attribute ask for.
+func mdStyled(n *html.Node, style mdStyle) string {
+	css := strings.ToLower(mdAttr(n, "style"))
+	bold := n.Data == "b" || n.Data == "strong" || mdBoldStyle.MatchString(css)
+	italic := n.Data == "i" || n.Data == "em" || n.Data == "cite" || n.Data == "var" || mdItalicStyle.MatchString(css)
+	tags := []string{}
+	if n.Data == "u" || n.Data == "ins" || mdUnderlineStyle.MatchString(css) {
+		tags = append(tags, "u")
+	}
+	if n.Data == "s" || n.Data == "strike" || n.Data == "del" || mdStrikeStyle.MatchString(css) {
+		tags = append(tags, "del")
+	}
+	if n.Data == "sub" || mdSubStyle.MatchString(css) {
+		tags = append(tags, "sub")
+	}
+	if n.Data == "sup" || mdSuperStyle.MatchString(css) {
+		tags = append(tags, "sup")
+	}
+	inner := mdInline(mdChildren(n), mdStyle{
+		bold:   style.bold || bold,
+		italic: style.italic || italic,
+	})
+	for _, tag := range tags {
+		inner = mdWrap(inner, "<"+tag+">", "</"+tag+">")
+	}
+	if italic && !style.italic {
+		inner = mdWrap(inner, "*", "*")
+	}
+	if bold && !style.bold {
+		inner = mdWrap(inner, "**", "**")
+	}
+	return inner
+}
+
+// mdWrap moves surrounding whitespace outside of the delimiters, since
+// emphasis like "** bold **" is not recognised by Markdown parsers.
+func mdWrap(s string, open string, close string) string {
+	core := strings.TrimSpace(s)
+	if core == "" {
+		return s
+	}
+	start := strings.Index(s, core)
+	return s[:start] + open + core + close + s[start+len(core):]
+}
+
+func mdList(n *html.Node) string {
+	ordered := n.Data == "ol"
+	number := 1
+	if start, err := strconv.Atoi(mdAttr(n, "start")); err == nil {
+		number = start
+	}
+	items := [][]mdBlock{}
+	for _, c := range mdChildren(n) {
+		switch {
+		case c.Type == html.ElementNode && c.Data == "li":
+			items = append(items, mdBlocks(mdChildren(c)))
+		case c.Type == html.ElementNode && (c.Data == "ul" || c.Data == "ol") && len(items) > 0:
+			// Indenting in the editor nests a list directly inside its parent
+			// list instead of inside the previous item.
+			items[len(items)-1] = append(items[len(items)-1], mdBlockNode(c)...)
+		default:
+			if blocks := mdBlocks([]*html.Node{c}); len(blocks) > 0 {
+				items = append(items, blocks)
+			}
+		}
+	}
+	lines := []string{}
+	for i := range items {
+		text := mdJoin(items[i])
+		if text == "" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines = append(lines, marker+mdPrefixLines(text, indent, "")[len(indent):])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mdCodeBlock(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			sb.WriteString("\n")
+		case n.Type == html.ElementNode && (n.Data == "div" || n.Data == "p"):
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+			if !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+		case n.Type == html.ElementNode:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c)
+	}
+	code := strings.Trim(mdClean(sb.String()), "\n")
+	language := mdLanguage(n)
+	for c := n.FirstChild; c != nil && language == ""; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "code" {
+			language = mdLanguage(c)
+		}
+	}
+	fence := "~~~"
+	for strings.Contains(code, fence) {
+		fence += "~"
+	}
+	if language != "" {
+		return fence + " " + language + "\n" + code + "\n" + fence
+	}
+	return fence + "\n" + code + "\n" + fence
+}
+
+func mdLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(mdAttr(n, "class")) {
+		if strings.HasPrefix(class, "language-") {
+			return strings.TrimPrefix(class, "language-")
+		}
+		if strings.HasPrefix(class, "lang-") {
+			return strings.TrimPrefix(class, "lang-")
+		}
+	}
+	return ""
+}
+
+func mdCodeSpan(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+	return fence + code + fence
+}
+
+// mdParagraphs splits rendered inline text on blank lines and joins the
+// remaining lines with hard line breaks.
+func mdParagraphs(s string) []string {
+	paragraphs := []string{}
+	lines := []string{}
+	flush := func() {
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "  \n"))
+		}
+		lines = []string{}
+	}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, mdLineStart.ReplaceAllStringFunc(line, mdEscapeLineStart))
+	}
+	flush()
+	return paragraphs
+}
+
+func mdEscapeLineStart(s string) string {
+	return s[:len(s)-1] + `\` + s[len(s)-1:]
+}
+
+func mdJoin(blocks []mdBlock) string {
+	var sb strings.Builder
+	for i := range blocks {
+		if i > 0 {
+			// A list directly after a line of text stays attached to it so
+			// that nested lists keep list items tight.
+			if blocks[i].list && !blocks[i-1].list {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(blocks[i].text)
+	}
+	return sb.String()
+}
+
+func mdPrefixLines(s string, prefix string, empty string) string {
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		if lines[i] == "" {
+			lines[i] = empty
+			continue
+		}
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mdEscape(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		switch r {
+		case '\\', '`', '*', '[', ']', '<', '>', '|':
+			sb.WriteRune('\\')
+		case '_':
+			// Underscores inside words never start emphasis, so snake_case
+			// is left readable.
+			if i == 0 || i == len(runes)-1 || !mdWordRune(runes[i-1]) || !mdWordRune(runes[i+1]) {
+				sb.WriteRune('\\')
+			}
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func mdWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func mdDestination(url string) string {
+	url = strings.TrimSpace(url)
+	url = strings.Replace(url, " ", "%20", -1)
+	url = strings.Replace(url, "(", "%28", -1)
+	url = strings.Replace(url, ")", "%29", -1)
+	return url
+}
+
+func mdTitle(title string) string {
+	title = mdCollapse(title)
+	if strings.TrimSpace(title) == "" {
+		return ""
+	}
+	return ` "` + strings.Replace(strings.TrimSpace(title), `"`, "&quot;", -1) + `"`
+}
+
+// mdClean removes the zero width characters the editor uses as cursor anchors
+// and turns non-breaking spaces into plain spaces.
+func mdClean(s string) string {
+	s = strings.Replace(s, "\u200b", "", -1)
+	s = strings.Replace(s, "\u200c", "", -1)
+	s = strings.Replace(s, "\u00a0", " ", -1)
+	return s
+}
+
+func mdCollapse(s string) string {
+	return mdWhitespace.ReplaceAllString(mdClean(s), " ")
+}
+
+func mdText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(mdText(c))
+	}
+	return sb.String()
+}
+
+func mdAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func mdChildren(n *html.Node) []*html.Node {
+	children := []*html.Node{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, c)
+	}
+	return children
+}
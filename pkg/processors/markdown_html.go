@@ -0,0 +1,690 @@
+package processors
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	mhFence      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	mhHighlight  = regexp.MustCompile(`^\s*{%-?\s*highlight\s+([^\s%]+)[^%]*-?%}\s*$`)
+	mhEndHilite  = regexp.MustCompile(`^\s*{%-?\s*endhighlight\s*-?%}\s*$`)
+	mhHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mhSetext     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mhRule       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mhQuote      = regexp.MustCompile(`^ {0,3}> ?`)
+	mhListItem   = regexp.MustCompile(`^( {0,3})([-*+]|(\d{1,9})([.)]))([ \t]+|$)`)
+	mhHTMLBlock  = regexp.MustCompile(`^ {0,3}(?:<!--|</?(?:address|article|aside|audio|blockquote|center|details|div|dl|figcaption|figure|footer|form|h[1-6]|header|hr|iframe|nav|ol|p|pre|script|section|style|table|ul|video)(?:[\s/>]|$))`)
+	mhReference  = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+	mhInlineHTML = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>)`)
+	mhAutolink   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	mhEmail      = regexp.MustCompile(`^<([^\s@<>]+@[^\s@<>]+)>`)
+	mhEntity     = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+type mhReferenceLink struct {
+	href  string
+	title string
+}
+
+// markdownParser converts Markdown to HTML, keeping the link reference
+// definitions of the document.
+type markdownParser struct {
+	references map[string]mhReferenceLink
+}
+
+// MarkdownToHTML converts Markdown written outside of the editor, such as the
+// bodies of imported Jekyll posts, to the HTML the editor writes, so that
+// every revision body is HTML. It reads the CommonMark that htmlToMarkdown
+// writes along with reference links, strikethrough, Jekyll highlight tags
+// and inline HTML, which is kept as it is.
+func MarkdownToHTML(markdown string) string {
+	lines := strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n")
+	p := markdownParser{references: map[string]mhReferenceLink{}}
+	return p.blocks(p.collectReferences(lines), false)
+}
+
+// collectReferences removes the link reference definitions outside of code
+// blocks and returns the remaining lines.
+func (p *markdownParser) collectReferences(lines []string) []string {
+	kept := []string{}
+	fence := ""
+	for _, line := range lines {
+		if m := mhFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && m[2] == "":
+				fence = ""
+			}
+		}
+		if m := mhReference.FindStringSubmatch(line); m != nil && fence == "" {
+			label := mhLabel(m[1])
+			if _, exists := p.references[label]; !exists {
+				p.references[label] = mhReferenceLink{href: mhUnescape(m[2]), title: mhUnescape(m[3] + m[4] + m[5])}
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// blocks converts lines of block content. The paragraphs of tight list items
+// are written without <p> elements.
+func (p *markdownParser) blocks(lines []string, tight bool) string {
+	var out strings.Builder
+	paragraph := []string{}
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := p.inline(strings.Join(paragraph, "\n"))
+		if tight {
+			out.WriteString(text)
+		} else {
+			out.WriteString("<p>" + text + "</p>")
+		}
+		paragraph = []string{}
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			flush()
+			i++
+			continue
+		}
+		if m := mhFence.FindStringSubmatch(line); m != nil {
+			flush()
+			code := []string{}
+			i++
+			for ; i < len(lines); i++ {
+				if end := mhFence.FindStringSubmatch(lines[i]); end != nil && strings.HasPrefix(end[1], m[1]) && end[2] == "" {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			out.WriteString(mhCodeBlock(code, m[2]))
+			continue
+		}
+		if m := mhHighlight.FindStringSubmatch(line); m != nil {
+			flush()
+			code := []string{}
+			i++
+			for ; i < len(lines); i++ {
+				if mhEndHilite.MatchString(lines[i]) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			out.WriteString(mhCodeBlock(code, m[1]))
+			continue
+		}
+		if len(paragraph) == 0 && mhIndent(line) >= 4 {
+			code := []string{}
+			for ; i < len(lines) && (strings.TrimSpace(lines[i]) == "" || mhIndent(lines[i]) >= 4); i++ {
+				code = append(code, mhDedent(lines[i], 4))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			out.WriteString(mhCodeBlock(code, ""))
+			continue
+		}
+		if m := mhHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			level := strconv.Itoa(len(m[1]))
+			out.WriteString("<h" + level + ">" + p.inline(m[2]) + "</h" + level + ">")
+			i++
+			continue
+		}
+		if m := mhSetext.FindStringSubmatch(line); m != nil && len(paragraph) > 0 {
+			level := "2"
+			if m[1][0] == '=' {
+				level = "1"
+			}
+			out.WriteString("<h" + level + ">" + p.inline(strings.Join(paragraph, "\n")) + "</h" + level + ">")
+			paragraph = []string{}
+			i++
+			continue
+		}
+		if mhRule.MatchString(line) {
+			flush()
+			out.WriteString("<hr>")
+			i++
+			continue
+		}
+		if mhQuote.MatchString(line) {
+			flush()
+			quoted := []string{}
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				quoted = append(quoted, mhQuote.ReplaceAllString(lines[i], ""))
+			}
+			out.WriteString("<blockquote>" + p.blocks(quoted, false) + "</blockquote>")
+			continue
+		}
+		if m := mhListItem.FindStringSubmatch(line); m != nil && (len(paragraph) == 0 || mhInterrupts(m)) {
+			flush()
+			list, next := p.list(lines, i)
+			out.WriteString(list)
+			i = next
+			continue
+		}
+		if mhHTMLBlock.MatchString(line) {
+			flush()
+			raw := []string{}
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				raw = append(raw, lines[i])
+			}
+			out.WriteString(strings.Join(raw, "\n"))
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimLeft(line, " \t"))
+		i++
+	}
+	flush()
+	return out.String()
+}
+
+// mhInterrupts reports whether a list item can start in the middle of a
+// paragraph, which numbered items other than 1 and empty items cannot.
+func mhInterrupts(m []string) bool {
+	if m[5] == "" {
+		return false
+	}
+	return m[3] == "" || m[3] == "1"
+}
+
+// list converts the list starting at lines[start] and returns it along with
+// the index of the line after it. Items continue on lines indented past their
+// marker, and a list with blank lines between its items is loose, with its
+// paragraphs in <p> elements.
+func (p *markdownParser) list(lines []string, start int) (string, int) {
+	first := mhListItem.FindStringSubmatch(lines[start])
+	ordered := first[3] != ""
+	kind := first[2]
+	if ordered {
+		kind = first[4]
+	}
+	items := [][]string{}
+	loose := false
+	blank := false
+	i := start
+	for i < len(lines) {
+		m := mhListItem.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		item_kind := m[2]
+		if m[3] != "" {
+			item_kind = m[4]
+		}
+		if item_kind != kind {
+			break
+		}
+		if blank {
+			loose = true
+		}
+		indent := len(m[0])
+		if m[5] == "" {
+			indent++
+		} else if len(m[5]) > 4 {
+			indent = len(m[0]) - len(m[5]) + 1
+		}
+		item := []string{""}
+		if indent < len(lines[i]) {
+			item = []string{lines[i][indent:]}
+		}
+		i++
+		blank = false
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				blank = true
+				item = append(item, "")
+				continue
+			}
+			if mhIndent(line) >= indent {
+				if blank {
+					loose = true
+				}
+				blank = false
+				item = append(item, mhDedent(line, indent))
+				continue
+			}
+			if blank || mhListItem.MatchString(line) || mhRule.MatchString(line) || mhHeading.MatchString(line) || mhQuote.MatchString(line) || mhFence.MatchString(line) {
+				break
+			}
+			// Lazy continuation of the last paragraph of the item
+			item = append(item, line)
+		}
+		for len(item) > 0 && strings.TrimSpace(item[len(item)-1]) == "" {
+			item = item[:len(item)-1]
+		}
+		items = append(items, item)
+	}
+	var out strings.Builder
+	if ordered {
+		number, _ := strconv.Atoi(first[3])
+		if number != 1 {
+			out.WriteString(`<ol start="` + strconv.Itoa(number) + `">`)
+		} else {
+			out.WriteString("<ol>")
+		}
+	} else {
+		out.WriteString("<ul>")
+	}
+	for _, item := range items {
+		out.WriteString("<li>" + p.blocks(item, !loose) + "</li>")
+	}
+	if ordered {
+		out.WriteString("</ol>")
+	} else {
+		out.WriteString("</ul>")
+	}
+	return out.String(), i
+}
+
+func mhCodeBlock(code []string, language string) string {
+	class := ""
+	if language != "" {
+		class = ` class="language-` + html.EscapeString(mhUnescape(language)) + `"`
+	}
+	return "<pre" + class + "><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>"
+}
+
+// mhIndent returns the width of the indentation of line, with tabs to the
+// next multiple of 4.
+func mhIndent(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// mhDedent removes up to width columns of indentation from line.
+func mhDedent(line string, width int) string {
+	removed := 0
+	for i, c := range line {
+		if removed >= width {
+			return line[i:]
+		}
+		switch c {
+		case ' ':
+			removed++
+		case '\t':
+			tab := 4 - removed%4
+			if removed+tab > width {
+				return strings.Repeat(" ", removed+tab-width) + line[i+1:]
+			}
+			removed += tab
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// mhLabel normalizes a link label, which is matched without case and with
+// runs of whitespace collapsed.
+func mhLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// mhUnescape removes the backslashes escaping punctuation and decodes entities.
+func mhUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && mhPunctuation(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return html.UnescapeString(sb.String())
+}
+
+func mhPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func mhSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func mhAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// inline converts the inline content of a paragraph or heading.
+func (p *markdownParser) inline(s string) string {
+	out := []byte{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			out = append(out, "<br>\n"...)
+			i += 2
+		case c == '\\' && i+1 < len(s) && mhPunctuation(s[i+1]):
+			out = append(out, html.EscapeString(s[i+1:i+2])...)
+			i += 2
+		case c == '`':
+			text, n := mhCodeSpan(s[i:])
+			out = append(out, text...)
+			i += n
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, n := p.link(s[i+1:], true); n > 0 {
+				out = append(out, text...)
+				i += n + 1
+				continue
+			}
+			out = append(out, '!')
+			i++
+		case c == '[':
+			if text, n := p.link(s[i:], false); n > 0 {
+				out = append(out, text...)
+				i += n
+				continue
+			}
+			out = append(out, '[')
+			i++
+		case c == '<':
+			if m := mhAutolink.FindStringSubmatch(s[i:]); m != nil {
+				out = append(out, `<a href="`+html.EscapeString(m[1])+`">`+html.EscapeString(m[1])+"</a>"...)
+				i += len(m[0])
+			} else if m := mhEmail.FindStringSubmatch(s[i:]); m != nil {
+				out = append(out, `<a href="mailto:`+html.EscapeString(m[1])+`">`+html.EscapeString(m[1])+"</a>"...)
+				i += len(m[0])
+			} else if m := mhInlineHTML.FindString(s[i:]); m != "" {
+				out = append(out, m...)
+				i += len(m)
+			} else {
+				out = append(out, "&lt;"...)
+				i++
+			}
+		case c == '&':
+			if m := mhEntity.FindString(s[i:]); m != "" {
+				out = append(out, m...)
+				i += len(m)
+			} else {
+				out = append(out, "&amp;"...)
+				i++
+			}
+		case c == '*' || c == '_' || c == '~':
+			text, n := p.emphasis(s, i)
+			out = append(out, text...)
+			i += n
+		case c == '\n':
+			trimmed := strings.TrimRight(string(out), " ")
+			if len(out)-len(trimmed) >= 2 {
+				trimmed += "<br>"
+			}
+			out = append([]byte(trimmed), '\n')
+			i++
+		default:
+			out = append(out, html.EscapeString(s[i:i+1])...)
+			i++
+		}
+	}
+	return strings.TrimRight(string(out), " ")
+}
+
+// mhCodeSpan converts the code span starting s and returns it along with the
+// length of Markdown it took up. Backticks that do not start a code span are
+// kept as they are.
+func mhCodeSpan(s string) (string, int) {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	fence := s[:n]
+	for end := n; end < len(s); {
+		j := strings.Index(s[end:], fence)
+		if j < 0 {
+			break
+		}
+		j += end
+		k := j + n
+		if k < len(s) && s[k] == '`' {
+			for k < len(s) && s[k] == '`' {
+				k++
+			}
+			end = k
+			continue
+		}
+		code := strings.Replace(s[n:j], "\n", " ", -1)
+		if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		return "<code>" + html.EscapeString(code) + "</code>", k
+	}
+	return fence, n
+}
+
+// emphasis converts the emphasis starting at s[i] and returns it along with
+// the length of Markdown it took up. The emphasis ends at the next run of the
+// same delimiters that can close it, and underscores inside words are kept as
+// they are.
+func (p *markdownParser) emphasis(s string, i int) (string, int) {
+	c := s[i]
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	run := s[i : i+n]
+	opens := i+n < len(s) && !mhSpace(s[i+n])
+	if c == '_' && i > 0 && mhAlphanumeric(s[i-1]) {
+		opens = false
+	}
+	if c == '~' && n != 2 || n > 3 || !opens {
+		return run, n
+	}
+	for j := i + n; j < len(s); {
+		if s[j] == '\\' {
+			j += 2
+			continue
+		}
+		if s[j] == '`' {
+			_, skip := mhCodeSpan(s[j:])
+			j += skip
+			continue
+		}
+		if s[j] != c {
+			j++
+			continue
+		}
+		k := j
+		for k < len(s) && s[k] == c {
+			k++
+		}
+		closes := k-j == n && !mhSpace(s[j-1])
+		if c == '_' && k < len(s) && mhAlphanumeric(s[k]) {
+			closes = false
+		}
+		if !closes {
+			j = k
+			continue
+		}
+		inner := p.inline(s[i+n : j])
+		switch {
+		case c == '~':
+			return "<del>" + inner + "</del>", k - i
+		case n == 1:
+			return "<em>" + inner + "</em>", k - i
+		case n == 2:
+			return "<strong>" + inner + "</strong>", k - i
+		default:
+			return "<strong><em>" + inner + "</em></strong>", k - i
+		}
+	}
+	return run, n
+}
+
+// link converts the link or image starting s with its label in brackets, and
+// returns it along with the length of Markdown it took up, which is 0 when s
+// does not start a link.
+func (p *markdownParser) link(s string, image bool) (string, int) {
+	end := mhCloseBracket(s)
+	if end < 0 {
+		return "", 0
+	}
+	label := s[1:end]
+	rest := s[end+1:]
+	var href, title string
+	n := end + 1
+	if dest, t, length := mhDestination(rest); length > 0 {
+		href, title = dest, t
+		n += length
+	} else {
+		ref := label
+		if strings.HasPrefix(rest, "[") {
+			if ref_end := strings.IndexByte(rest, ']'); ref_end >= 0 {
+				if ref_end > 1 {
+					ref = rest[1:ref_end]
+				}
+				n += ref_end + 1
+			}
+		}
+		reference, exists := p.references[mhLabel(ref)]
+		if !exists {
+			return "", 0
+		}
+		href, title = reference.href, reference.title
+	}
+	title_attr := ""
+	if title != "" {
+		title_attr = ` title="` + html.EscapeString(title) + `"`
+	}
+	if image {
+		alt := html.UnescapeString(mhPlainText(p.inline(label)))
+		return `<img src="` + html.EscapeString(href) + `" alt="` + html.EscapeString(alt) + `"` + title_attr + ">", n
+	}
+	return `<a href="` + html.EscapeString(href) + `"` + title_attr + ">" + p.inline(label) + "</a>", n
+}
+
+// mhCloseBracket returns the index of the bracket closing the one starting s,
+// or -1 when there is none.
+func mhCloseBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			_, n := mhCodeSpan(s[i:])
+			i += n - 1
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// mhDestination reads the destination and title in parentheses after the
+// label of an inline link, returning the length they took up, which is 0 when
+// s does not start with one.
+func mhDestination(s string) (string, string, int) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", 0
+	}
+	i := 1
+	for i < len(s) && mhSpace(s[i]) {
+		i++
+	}
+	var dest string
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return "", "", 0
+		}
+		dest = s[i+1 : i+end]
+		i += end + 1
+	} else {
+		start := i
+		depth := 0
+		for ; i < len(s) && !mhSpace(s[i]); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '(' {
+				depth++
+			}
+			if s[i] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		if i > len(s) {
+			i = len(s)
+		}
+		dest = s[start:i]
+	}
+	for i < len(s) && mhSpace(s[i]) {
+		i++
+	}
+	title := ""
+	if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		close := s[i]
+		if close == '(' {
+			close = ')'
+		}
+		end := -1
+		for j := i + 1; j < len(s); j++ {
+			if s[j] == '\\' {
+				j++
+				continue
+			}
+			if s[j] == close {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return "", "", 0
+		}
+		title = s[i+1 : end]
+		i = end + 1
+		for i < len(s) && mhSpace(s[i]) {
+			i++
+		}
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0
+	}
+	return mhUnescape(dest), mhUnescape(title), i + 1
+}
+
+// mhPlainText removes the tags from converted inline content, for the alt
+// text of images.
+func mhPlainText(s string) string {
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return sb.String()
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(string(z.Text())))
+		}
+	}
+}
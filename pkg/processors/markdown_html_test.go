@@ -0,0 +1,89 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"empty", ``, ``},
+		{"paragraphs", "one\ntwo\n\nthree", "<p>one\ntwo</p><p>three</p>"},
+		{"line break", "one  \ntwo\\\nthree", "<p>one<br>\ntwo<br>\nthree</p>"},
+		{"headings", "# Title #\n\nSub *title*\n---\n\nTop\n===", "<h1>Title</h1><h2>Sub <em>title</em></h2><h1>Top</h1>"},
+		{"emphasis", "**bold** *italic* __bold__ _italic_ ***both*** ~~strike~~", "<p><strong>bold</strong> <em>italic</em> <strong>bold</strong> <em>italic</em> <strong><em>both</em></strong> <del>strike</del></p>"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>"},
+		{"not emphasis", "snake_case_name 2 * 3 * 4 **", "<p>snake_case_name 2 * 3 * 4 **</p>"},
+		{"escapes", `\*not\* \[link\] a \\ b`, `<p>*not* [link] a \ b</p>`},
+		{"html text", `a < b && c > "d" &copy;`, "<p>a &lt; b &amp;&amp; c &gt; &#34;d&#34; &copy;</p>"},
+		{"inline html", `<u>under</u> x<sub>2</sub> <span class="a">b</span>`, `<p><u>under</u> x<sub>2</sub> <span class="a">b</span></p>`},
+		{"code span", "`a <b>` `` c`d ``", "<p><code>a &lt;b&gt;</code> <code>c`d</code></p>"},
+		{"unclosed code span", "``a`", "<p>``a`</p>"},
+		{"link", `[a *b*](https://example.com/a_b "A \"title\"")`, `<p><a href="https://example.com/a_b" title="A &#34;title&#34;">a <em>b</em></a></p>`},
+		{"link with parentheses", `[a](https://example.com/a_(b))`, `<p><a href="https://example.com/a_(b)">a</a></p>`},
+		{"reference links", "[one][1], [Two][] and [two]\n\n[1]: https://example.com/1\n[two]: <https://example.com/2> 'Two'", `<p><a href="https://example.com/1">one</a>, <a href="https://example.com/2" title="Two">Two</a> and <a href="https://example.com/2" title="Two">two</a></p>`},
+		{"undefined reference", "[a][b] [c]", "<p>[a][b] [c]</p>"},
+		{"image", `![a *cat*](/assets/cat.png "Cat")`, `<p><img src="/assets/cat.png" alt="a cat" title="Cat"></p>`},
+		{"autolinks", "<https://example.com> <me@example.com>", `<p><a href="https://example.com">https://example.com</a> <a href="mailto:me@example.com">me@example.com</a></p>`},
+		{"unordered list", "- one\n- two\n  continued\n* other", "<ul><li>one</li><li>two\ncontinued</li></ul><ul><li>other</li></ul>"},
+		{"ordered list", "3. three\n4. four", `<ol start="3"><li>three</li><li>four</li></ol>`},
+		{"nested list", "- one\n  - nested\n    1. deep\n- two", "<ul><li>one<ul><li>nested<ol><li>deep</li></ol></li></ul></li><li>two</li></ul>"},
+		{"loose list", "- one\n\n  more\n- two", "<ul><li><p>one</p><p>more</p></li><li><p>two</p></li></ul>"},
+		{"list after paragraph", "Items:\n- one\n\nIn 2020\n2. not a list", "<p>Items:</p><ul><li>one</li></ul><p>In 2020\n2. not a list</p>"},
+		{"blockquote", "> one\n>\n> two\nlazy", "<blockquote><p>one</p><p>two\nlazy</p></blockquote>"},
+		{"rules", "a\n\n* * *\n\n___", "<p>a</p><hr><hr>"},
+		{"fenced code", "~~~ go\nfunc main() {\n\treturn <nil>\n}\n~~~\n\n```\n# not a heading\n```", "<pre class=\"language-go\"><code>func main() {\n\treturn &lt;nil&gt;\n}</code></pre><pre><code># not a heading</code></pre>"},
+		{"indented code", "    one\n\n\ttwo\n\nthree", "<pre><code>one\n\ntwo</code></pre><p>three</p>"},
+		{"highlight tag", "{% highlight ruby linenos %}\ndef foo\n  puts 'foo'\nend\n{% endhighlight %}", "<pre class=\"language-ruby\"><code>def foo\n  puts &#39;foo&#39;\nend</code></pre>"},
+		{"html block", "<table>\n<tr><td>*a*</td></tr>\n</table>\n\n*b*", "<table>\n<tr><td>*a*</td></tr>\n</table><p><em>b</em></p>"},
+		{"windows line endings", "one\r\n\r\ntwo", "<p>one</p><p>two</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToHTML(tt.markdown); got != tt.want {
+				t.Errorf("MarkdownToHTML(%q) =\n%q\nwant\n%q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarkdownRoundTrip checks that Markdown written by htmlToMarkdown is
+// read back as the same post.
+func TestMarkdownRoundTrip(t *testing.T) {
+	tests := []string{
+		"hello world\n",
+		"one\n\ntwo\n",
+		"one  \ntwo\n",
+		"# Title\n\n### Sub **title**\n",
+		"**bold** *italic* ***both***\n",
+		"<u>under</u> <del>strike</del> x<sub>2</sub> x<sup>2</sup>\n",
+		"[link](https://example.com/a%20b%28c%29 \"A &quot;title&quot;\")\n",
+		"![a \\[cat\\]](/assets/1-cat.png)\n",
+		"1 \\* 2 \\[a\\] **\\_c\\_ snake_case \\\\ \\|**\n",
+		"\\<script\\>\n",
+		"\\# not a heading\n\n1\\. not a list\n\n\\- not an item\n",
+		"``a `b` c``\n",
+		"~~~ go\nfunc main() {\n\treturn\n}\n~~~\n",
+		"~~~~\n~~~\nx\n~~~~\n",
+		"- one\n- two\n",
+		"3. three\n4. four\n",
+		"- one\n  - nested\n- two\n",
+		"Items:\n- one\n",
+		"> one\n>\n> two\n",
+		"a\n\n* * *\n\nb\n",
+		"<table><tbody><tr><td>a</td></tr></tbody></table>\n",
+	}
+	for _, markdown := range tests {
+		body := MarkdownToHTML(markdown)
+		got, err := htmlToMarkdown(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != markdown {
+			t.Errorf("%q is read as %q and written back as %q", markdown, body, got)
+		}
+	}
+}
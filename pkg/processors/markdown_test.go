@@ -0,0 +1,56 @@
+package processors
+
+import (
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"empty", ``, ``},
+		{"text", `hello world`, "hello world\n"},
+		{"paragraphs", `<p>one</p><p>two</p>`, "one\n\ntwo\n"},
+		{"editor lines", `<div>one</div><div>two</div>`, "one\n\ntwo\n"},
+		{"line break", `one<br>two`, "one  \ntwo\n"},
+		{"collapsed whitespace", "<p>one \n\t two</p>", "one two\n"},
+		{"non-breaking space", "one&nbsp;two\u200b", "one two\n"},
+		{"headings", `<h1>Title</h1><h3>Sub <b>title</b></h3>`, "# Title\n\n### Sub **title**\n"},
+		{"bold and italic", `<b>bold</b> <i>italic</i> <strong><em>both</em></strong>`, "**bold** *italic* ***both***\n"},
+		{"styled spans", `<span style="font-weight: bold;">bold</span> <span style="font-style: italic;">italic</span>`, "**bold** *italic*\n"},
+		{"nested bold", `<b>one <span style="font-weight: 700">two</span></b>`, "**one two**\n"},
+		{"whitespace outside emphasis", `a<b> bold </b>b`, "a **bold** b\n"},
+		{"inline html", `<u>under</u> <s>strike</s> x<sub>2</sub> x<sup>2</sup>`, "<u>under</u> <del>strike</del> x<sub>2</sub> x<sup>2</sup>\n"},
+		{"styled inline html", `<span style="text-decoration: underline line-through;">both</span>`, "<del><u>both</u></del>\n"},
+		{"link", `<a href="https://example.com/a b(c)" title="A &quot;title&quot;">link</a>`, "[link](https://example.com/a%20b%28c%29 \"A &quot;title&quot;\")\n"},
+		{"empty link", `<a href="https://example.com"></a>`, ""},
+		{"image", `<img src="/assets/1-cat.png" alt="a [cat]">`, "![a \\[cat\\]](/assets/1-cat.png)\n"},
+		{"escaped text", `1 * 2 [a] <b>_c_ snake_case \ |</b>`, "1 \\* 2 \\[a\\] **\\_c\\_ snake_case \\\\ \\|**\n"},
+		{"escaped html text", `&lt;script&gt;`, "\\<script\\>\n"},
+		{"escaped line start", `<p># not a heading</p><p>1. not a list</p><p>- not an item</p>`, "\\# not a heading\n\n1\\. not a list\n\n\\- not an item\n"},
+		{"code span", "<code>a `b` c</code>", "``a `b` c``\n"},
+		{"code block", `<pre class="language-go">func main() {<br>	return<br>}</pre>`, "~~~ go\nfunc main() {\n\treturn\n}\n~~~\n"},
+		{"code block with fence", "<pre><code>~~~\nx</code></pre>", "~~~~\n~~~\nx\n~~~~\n"},
+		{"unordered list", `<ul><li>one</li><li>two</li></ul>`, "- one\n- two\n"},
+		{"ordered list", `<ol start="3"><li>three</li><li>four</li></ol>`, "3. three\n4. four\n"},
+		{"nested list", `<ul><li>one</li><ul><li>nested</li></ul><li>two</li></ul>`, "- one\n  - nested\n- two\n"},
+		{"list after text", `<p>Items:</p><ul><li>one</li></ul>`, "Items:\n- one\n"},
+		{"blockquote", `<blockquote><p>one</p><p>two</p></blockquote>`, "> one\n>\n> two\n"},
+		{"rule", `<p>a</p><hr><p>b</p>`, "a\n\n* * *\n\nb\n"},
+		{"table", `<table><tbody><tr><td>a</td></tr></tbody></table>`, "<table><tbody><tr><td>a</td></tr></tbody></table>\n"},
+		{"script", `<p>a<script>alert(1)</script></p>`, "a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := htmlToMarkdown(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("htmlToMarkdown(%q) =\n%q\nwant\n%q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+func (prcr Processor) GetPost(post_id int64, method string) (*database.CompletePost, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return prcr.completePost(db_post, method)
+}
+
+func (prcr Processor) CreatePost(p post.Post) (*database.CompletePost, apierror.IApiError) {
+	err := p.Validate()
+	if err != nil {
+		apiErr := apierror.New(fmt.Errorf("invalid create post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	db_post, err := prcr.db.GetPostByUrlTitle(p.UrlTitle())
+	if err != nil {
+		msg := "error getting post in CreatePost: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	if db_post != nil {
+		msg := "post '" + p.Title + "' already exists, update it instead"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	_, err = prcr.db.CreatePost(p, database.DB_FALSE())
+	if err != nil {
+		_, apiErr := saveError("cannot create post: ", err, p.Method())
+		return nil, apiErr
+	}
+	db_post, err = prcr.db.GetPostByUrlTitle(p.UrlTitle())
+	if err != nil {
+		msg := "error getting created post in CreatePost: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	if db_post == nil {
+		msg := "no post found after CreatePost"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	return prcr.completePost(db_post, p.Method())
+}
+
+// UpdatePost stores p as a new revision of a post. When the post has changed
+// since the revision p was edited from, the post is returned along with a
+// CONFLICT error.
+func (prcr Processor) UpdatePost(post_id int64, p post.Post) (*database.CompletePost, apierror.IApiError) {
+	err := p.Validate()
+	if err != nil {
+		apiErr := apierror.New(fmt.Errorf("invalid update post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	db_post, apiErr := prcr.findPost(post_id, p.Method())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if db_post.Posted == database.DB_TRUE().Value() {
+		return prcr.UpdatePublishedPost(post_id, p)
+	}
+	if !strings.EqualFold(db_post.UrlTitle, p.UrlTitle()) {
+		msg := "post title '" + p.Title + "' does not match post '" + db_post.Title + "'"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	_, err = prcr.db.CreatePost(p, database.DB_FALSE())
+	if err != nil {
+		_, apiErr := saveError("cannot update post: ", err, p.Method())
+		if apiErr.Status() == apierror.StatusConflict {
+			complete_post, _ := prcr.GetPost(post_id, p.Method())
+			return complete_post, apiErr
+		}
+		return nil, apiErr
+	}
+	return prcr.GetPost(post_id, p.Method())
+}
+
+func (prcr Processor) DeletePost(post_id int64, method string) apierror.IApiError {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	if db_post.Posted == database.DB_TRUE().Value() {
+		msg := "Post has already been posted for DeletePost"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	err := prcr.db.DeletePost(db_post)
+	if err != nil {
+		msg := "cannot delete post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return apiErr
+	}
+	return nil
+}
+
+func (prcr Processor) findPost(post_id int64, method string) (*database.Post, apierror.IApiError) {
+	db_post, err := prcr.db.GetPostById(post_id)
+	if err != nil {
+		msg := "error getting post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if db_post == nil {
+		msg := "no post exists with that id"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return db_post, nil
+}
+
+func (prcr Processor) completePost(db_post *database.Post, method string) (*database.CompletePost, apierror.IApiError) {
+	post, err := prcr.db.GetCompletePost(db_post)
+	if err != nil {
+		msg := "cannot get complete post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if post == nil {
+		msg := "no complete post exists"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return post, nil
+}
@@ -0,0 +1,18 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) Posts(method string) ([]database.Post, apierror.IApiError) {
+	posts, err := prcr.db.GetPosts()
+	if err != nil {
+		msg := "cannot get posts: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return posts, nil
+}
@@ -0,0 +1,139 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+type RenderedPost struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Preview renders p with the post template as it would be published if it
+// were submitted now. Nothing is saved or written to the site.
+func (prcr Processor) Preview(p post.Post) (*RenderedPost, apierror.IApiError) {
+	err := p.Validate()
+	if err != nil {
+		apiErr := apierror.New(fmt.Errorf("invalid preview post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	db_post, err := prcr.db.GetPostByUrlTitle(p.UrlTitle())
+	if err != nil {
+		msg := "error getting post " + p.UrlTitle() + ": " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	var user *database.User
+	if db_post != nil {
+		// Submitting adds a revision to the existing post, which keeps its
+		// author, date and file
+		user, err = prcr.db.GetUserById(db_post.UserID)
+		preview_post := *db_post
+		preview_post.Title = p.Title
+		preview_post.Format = p.Format
+		db_post = &preview_post
+	} else {
+		user, err = prcr.db.GetUserByUsername(p.Author())
+	}
+	if err != nil {
+		msg := "error getting user when previewing post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	if user == nil {
+		msg := "no user found when previewing post"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	now := time.Now().Unix()
+	if db_post == nil {
+		// Posts are stored with a lower case url_title
+		db_post = &database.Post{
+			UrlTitle:   strings.ToLower(p.UrlTitle()),
+			UserID:     user.ID,
+			Title:      p.Title,
+			Format:     p.Format,
+			UpdateTime: now,
+			InsertTime: now,
+		}
+	}
+	post_time := now
+	if db_post.PublishAt != nil {
+		post_time = *db_post.PublishAt
+	}
+	categories, apiErr := prcr.previewCategories(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	tags, apiErr := prcr.previewTags(p)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	data, apiErr := prcr.renderTemplate(generatedPost{
+		Post: db_post,
+		User: user,
+		LatestPost: &database.PostHistory{
+			PostID:     db_post.ID,
+			Body:       p.Body,
+			Method:     p.Method(),
+			InsertTime: now,
+		},
+		Categories: categories,
+		Tags:       tags,
+		Date:       time.Unix(post_time, 0).UTC(),
+	}, p.Method())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return &RenderedPost{
+		Name:    prcr.postName(db_post, post_time),
+		Content: string(data),
+	}, nil
+}
+
+// previewCategories returns the categories p would be saved with, using the
+// stored name of categories that already exist.
+func (prcr Processor) previewCategories(p post.Post) ([]database.Category, apierror.IApiError) {
+	names := p.UrlCategories()
+	categories := []database.Category{}
+	for i := range names {
+		category, err := prcr.db.GetCategoryByName(names[i])
+		if err != nil {
+			msg := "error getting category " + names[i] + ": " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+			return nil, apiErr
+		}
+		if category == nil {
+			category = &database.Category{Name: names[i]}
+		}
+		categories = append(categories, *category)
+	}
+	return categories, nil
+}
+
+// previewTags returns the tags p would be saved with, using the stored name of
+// tags that already exist.
+func (prcr Processor) previewTags(p post.Post) ([]database.Tag, apierror.IApiError) {
+	names := p.UrlTags()
+	tags := []database.Tag{}
+	for i := range names {
+		tag, err := prcr.db.GetTagByName(names[i])
+		if err != nil {
+			msg := "error getting tag " + names[i] + ": " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+			return nil, apiErr
+		}
+		if tag == nil {
+			tag = &database.Tag{Name: names[i]}
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,115 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func TestPreviewFormats(t *testing.T) {
+	body := `<p><b>Bold</b> text</p><ul><li>one</li></ul>`
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"html", FORMAT_HTML, body},
+		{"markdown", FORMAT_MARKDOWN, "**Bold** text\n- one\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prcr := newTestProcessor(t, Config{})
+			p := testPost("Previewed Post", body)
+			p.Format = tt.format
+			rendered, apiErr := prcr.Preview(p)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			if !strings.HasSuffix(rendered.Name, "-previewed-post.md") {
+				t.Errorf("previewed as %s", rendered.Name)
+			}
+			parts := strings.SplitN(rendered.Content, "---\n", 3)
+			if len(parts) != 3 || parts[0] != "" {
+				t.Fatalf("previewed post has no front matter:\n%s", rendered.Content)
+			}
+			if parts[2] != tt.want {
+				t.Errorf("previewed body is\n%q\nwant\n%q", parts[2], tt.want)
+			}
+			if !strings.Contains(parts[1], "title: Previewed Post") {
+				t.Errorf("previewed front matter is\n%s", parts[1])
+			}
+			// Previewing does not save the post
+			db_post, err := prcr.db.GetPostByUrlTitle(p.UrlTitle())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if db_post != nil {
+				t.Errorf("preview saved post %+v", db_post)
+			}
+		})
+	}
+}
+
+func TestPreviewExistingPost(t *testing.T) {
+	prcr, posts := newRecordingProcessor(t)
+	complete := createTestPost(t, prcr, "Previewed Post", "<p>first</p>")
+	published, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	rendered, apiErr := prcr.Preview(testPost("Previewed Post", "<p>second</p>"))
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	// The preview keeps the date and file of the published post
+	if rendered.Name != published.Post.PublishedName {
+		t.Errorf("previewed as %s, want %s", rendered.Name, published.Post.PublishedName)
+	}
+	if !strings.Contains(rendered.Content, "<p>second</p>") {
+		t.Errorf("preview does not have the new body:\n%s", rendered.Content)
+	}
+	if strings.Contains(string(posts.files[published.Post.PublishedName]), "second") {
+		t.Error("preview was written to the site")
+	}
+}
+
+func TestPreviewAssets(t *testing.T) {
+	for _, format := range []string{FORMAT_HTML, FORMAT_MARKDOWN} {
+		t.Run(format, func(t *testing.T) {
+			prcr, _ := newRecordingProcessor(t)
+			asset, apiErr := prcr.UploadAsset(testAdmin(t, prcr), "cat.png", testPNG, apierror.MethodHTTP)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			p := testPost("Asset Preview", `<p><img src="`+EditorAssetURL(asset)+`" alt="cat"></p>`)
+			p.Format = format
+			rendered, apiErr := prcr.Preview(p)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			site_url := "/assets/" + assetFile(asset)
+			if !strings.Contains(rendered.Content, site_url) {
+				t.Errorf("preview does not use the site url %s:\n%s", site_url, rendered.Content)
+			}
+			if strings.Contains(rendered.Content, "/api/assets/") {
+				t.Errorf("preview still uses the editor url:\n%s", rendered.Content)
+			}
+
+			p.Body = `<p><img src="/api/assets/12345/missing.png"></p>`
+			if _, apiErr := prcr.Preview(p); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+				t.Errorf("got %v previewing a missing asset, want %s", apiErr, apierror.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestPreviewInvalid(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	p := testPost("Previewed Post", "")
+	if _, apiErr := prcr.Preview(p); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v previewing a post without a body, want %s", apiErr, apierror.StatusBadRequest)
+	}
+}
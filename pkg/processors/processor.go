@@ -1,15 +1,23 @@
 package processors
 
-import "gitlab.com/joshraphael/motdoftheday/pkg/database"
+import (
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/publisher"
+)
 
 type Processor struct {
-	db  *database.Database
-	cfg Config
+	db        *database.Database
+	cfg       Config
+	publisher publisher.Publisher
+	assets    publisher.Publisher
 }
 
 func New(cfg Config, database *database.Database) Processor {
+	posts, assets := newPublishers(cfg)
 	return Processor{
-		db:  database,
-		cfg: cfg,
+		db:        database,
+		cfg:       cfg,
+		publisher: posts,
+		assets:    assets,
 	}
 }
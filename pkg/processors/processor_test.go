@@ -0,0 +1,60 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// newTestProcessor returns a Processor over a migrated database in a
+// temporary directory, publishing posts to a temporary directory when cfg has
+// none.
+func newTestProcessor(t *testing.T, cfg Config) Processor {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Database files are opened relative to the working directory
+	file, err := filepath.Rel(wd, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, sqlxDB, err := database.New(database.Config{File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	if cfg.Directory == "" {
+		cfg.Directory = filepath.Join(t.TempDir(), "_posts")
+	}
+	return New(cfg, db)
+}
+
+// testPost returns a post by the admin user with one tag and one category.
+func testPost(title string, body string) post.Post {
+	p := post.New(apierror.MethodHTTP, "admin")
+	p.Title = title
+	p.Tags = []string{"test"}
+	p.Categories = []string{"testing"}
+	p.Body = body
+	return p
+}
+
+func createTestPost(t *testing.T, prcr Processor, title string, body string) *database.CompletePost {
+	t.Helper()
+	complete, apiErr := prcr.CreatePost(testPost(title, body))
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	return complete
+}
@@ -0,0 +1,109 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+)
+
+// Publish publishes a draft right away. A post that was unpublished before
+// replaces its old file, keeping its original date.
+func (prcr Processor) Publish(post_id int64, method string) (*database.CompletePost, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if db_post.Posted == database.DB_TRUE().Value() {
+		msg := "Post has already been posted for Publish"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	apiErr = prcr.publishPost(db_post, method)
+	if apiErr != nil {
+		apiErr := apierror.New(fmt.Errorf("cannot publish post: %w", apiErr), apiErr.Status(), method)
+		return nil, apiErr
+	}
+	return prcr.GetPost(post_id, method)
+}
+
+// Unpublish removes the generated file of a posted post and turns it back
+// into a draft.
+func (prcr Processor) Unpublish(post_id int64, method string) (*database.CompletePost, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if db_post.Posted != database.DB_TRUE().Value() {
+		msg := "Post has not been posted for Unpublish"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	if db_post.PublishedName != "" {
+		message := "Unpublish \"" + db_post.Title + "\""
+		err := prcr.publisher.Delete(db_post.PublishedName, message)
+		if err != nil {
+			msg := "cannot remove post " + db_post.UrlTitle + ": " + err.Error()
+			apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+			return nil, apiErr
+		}
+	}
+	err := prcr.db.UnpublishPost(db_post)
+	if err != nil {
+		msg := "cannot unpublish post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return prcr.GetPost(post_id, method)
+}
+
+// UpdatePublishedPost stores p as a new revision of a posted post and
+// regenerates the same file from it. When the post has changed since the
+// revision p was edited from, the post is returned along with a CONFLICT
+// error.
+func (prcr Processor) UpdatePublishedPost(post_id int64, p post.Post) (*database.CompletePost, apierror.IApiError) {
+	err := p.Validate()
+	if err != nil {
+		apiErr := apierror.New(fmt.Errorf("invalid update published post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	db_post, apiErr := prcr.findPost(post_id, p.Method())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	_, apiErr = prcr.revisePublishedPost(db_post, p)
+	if apiErr != nil {
+		if apiErr.Status() == apierror.StatusConflict {
+			complete_post, _ := prcr.GetPost(post_id, p.Method())
+			return complete_post, apiErr
+		}
+		return nil, apiErr
+	}
+	return prcr.GetPost(post_id, p.Method())
+}
+
+func (prcr Processor) revisePublishedPost(db_post *database.Post, p post.Post) (*database.PostHistory, apierror.IApiError) {
+	if db_post.Posted != database.DB_TRUE().Value() {
+		msg := "Post has not been posted for UpdatePublishedPost"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	if !strings.EqualFold(db_post.UrlTitle, p.UrlTitle()) {
+		msg := "post title '" + p.Title + "' does not match post '" + db_post.Title + "'"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
+	}
+	post_history_id, err := prcr.db.RevisePublishedPost(p)
+	if err != nil {
+		return saveError("cannot update published post: ", err, p.Method())
+	}
+	apiErr := prcr.generatePost(p)
+	if apiErr != nil {
+		apiErr := apierror.New(fmt.Errorf("cannot generate post: %w", apiErr), apiErr.Status(), p.Method())
+		return nil, apiErr
+	}
+	return prcr.revision(*post_history_id, p.Method())
+}
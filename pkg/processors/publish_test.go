@@ -0,0 +1,167 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// recordingPublisher keeps published files in memory and records the names
+// deleted from it.
+type recordingPublisher struct {
+	files   map[string][]byte
+	writes  []string
+	deletes []string
+}
+
+func newRecordingPublisher() *recordingPublisher {
+	return &recordingPublisher{files: map[string][]byte{}}
+}
+
+func (p *recordingPublisher) Write(name string, data []byte, message string) error {
+	p.files[name] = data
+	p.writes = append(p.writes, name)
+	return nil
+}
+
+func (p *recordingPublisher) Delete(name string, message string) error {
+	delete(p.files, name)
+	p.deletes = append(p.deletes, name)
+	return nil
+}
+
+func (p *recordingPublisher) List() ([]string, error) {
+	names := []string{}
+	for name := range p.files {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func newRecordingProcessor(t *testing.T) (Processor, *recordingPublisher) {
+	t.Helper()
+	prcr := newTestProcessor(t, Config{})
+	posts := newRecordingPublisher()
+	prcr.publisher = posts
+	prcr.assets = newRecordingPublisher()
+	return prcr, posts
+}
+
+func TestPublish(t *testing.T) {
+	prcr, posts := newRecordingProcessor(t)
+	complete := createTestPost(t, prcr, "Published Post", "<p>body</p>")
+	published, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if published.Post.Posted != database.DB_TRUE().Value() {
+		t.Error("published post is not posted")
+	}
+	name := published.Post.PublishedName
+	if name == "" || len(posts.writes) != 1 || posts.writes[0] != name {
+		t.Fatalf("wrote %v, want the published name %q", posts.writes, name)
+	}
+	if !bytes.Contains(posts.files[name], []byte("<p>body</p>")) {
+		t.Errorf("published file is\n%s", posts.files[name])
+	}
+	if _, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v publishing a posted post, want %s", apiErr, apierror.StatusBadRequest)
+	}
+	if _, apiErr := prcr.Publish(12345, apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusNotFound {
+		t.Errorf("got %v publishing a missing post, want %s", apiErr, apierror.StatusNotFound)
+	}
+}
+
+func TestUnpublish(t *testing.T) {
+	prcr, posts := newRecordingProcessor(t)
+	complete := createTestPost(t, prcr, "Unpublished Post", "<p>body</p>")
+	if _, apiErr := prcr.Unpublish(complete.Post.ID, apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v unpublishing a draft, want %s", apiErr, apierror.StatusBadRequest)
+	}
+	published, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	name := published.Post.PublishedName
+
+	unpublished, apiErr := prcr.Unpublish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(posts.deletes) != 1 || posts.deletes[0] != name {
+		t.Errorf("deleted %v, want %q", posts.deletes, name)
+	}
+	if _, exists := posts.files[name]; exists {
+		t.Error("the published file is still there")
+	}
+	if unpublished.Post.Posted != database.DB_FALSE().Value() {
+		t.Error("unpublished post is still posted")
+	}
+	if unpublished.Post.PublishAt != nil {
+		t.Errorf("unpublished post is still scheduled at %d", *unpublished.Post.PublishAt)
+	}
+	if unpublished.Post.PublishedName != name {
+		t.Errorf("unpublished post forgot its published name %q, got %q", name, unpublished.Post.PublishedName)
+	}
+
+	// Publishing again replaces the same file rather than adding a new one
+	republished, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if republished.Post.PublishedName != name {
+		t.Errorf("republished as %q, want %q", republished.Post.PublishedName, name)
+	}
+	if len(posts.files) != 1 || posts.files[name] == nil {
+		t.Errorf("published files are %v, want only %q", posts.writes, name)
+	}
+}
+
+func TestUpdatePublishedPost(t *testing.T) {
+	prcr, posts := newRecordingProcessor(t)
+	complete := createTestPost(t, prcr, "Updated Post", "<p>first</p>")
+	if _, apiErr := prcr.UpdatePublishedPost(complete.Post.ID, testPost("Updated Post", "<p>draft</p>")); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v updating a draft, want %s", apiErr, apierror.StatusBadRequest)
+	}
+	published, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	name := published.Post.PublishedName
+	base := published.History[len(published.History)-1].ID
+
+	p := testPost("updated post", "<p>second</p>")
+	p.PostHistoryID = &base
+	updated, apiErr := prcr.UpdatePublishedPost(complete.Post.ID, p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if updated.Post.PublishedName != name || updated.Post.Posted != database.DB_TRUE().Value() {
+		t.Errorf("updated post is %+v, want it posted as %q", updated.Post, name)
+	}
+	if len(posts.files) != 1 || !bytes.Contains(posts.files[name], []byte("<p>second</p>")) {
+		t.Errorf("published files are %v, want %q with the update", posts.writes, name)
+	}
+
+	// Updating from the revision before the update is a conflict
+	p = testPost("Updated Post", "<p>stale</p>")
+	p.PostHistoryID = &base
+	conflict, apiErr := prcr.UpdatePublishedPost(complete.Post.ID, p)
+	if apiErr == nil || apiErr.Status() != apierror.StatusConflict {
+		t.Fatalf("got %v, want %s", apiErr, apierror.StatusConflict)
+	}
+	if conflict == nil || conflict.History[len(conflict.History)-1].Body != "<p>second</p>" {
+		t.Errorf("got %+v along with the conflict, want the updated post", conflict)
+	}
+	if bytes.Contains(posts.files[name], []byte("stale")) {
+		t.Error("a conflicting update was published")
+	}
+
+	if _, apiErr := prcr.UpdatePublishedPost(complete.Post.ID, testPost("Other Post", "<p>other</p>")); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v updating with another title, want %s", apiErr, apierror.StatusBadRequest)
+	}
+}
@@ -0,0 +1,19 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+// PublishedFiles returns the name of every file the publisher holds in the
+// post directory of the site.
+func (prcr Processor) PublishedFiles(method string) ([]string, apierror.IApiError) {
+	names, err := prcr.publisher.List()
+	if err != nil {
+		msg := "cannot list published files: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return names, nil
+}
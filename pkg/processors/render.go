@@ -0,0 +1,28 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+// Render returns the file name and contents a post would be published with,
+// without publishing it.
+func (prcr Processor) Render(post_id int64, method string) (string, []byte, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return "", nil, apiErr
+	}
+	user, err := prcr.db.GetUserById(db_post.UserID)
+	if err != nil {
+		msg := "error getting user when rendering post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	if user == nil {
+		msg := "no user found when rendering post"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return "", nil, apiErr
+	}
+	return prcr.renderPost(db_post, user, method)
+}
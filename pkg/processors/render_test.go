@@ -0,0 +1,51 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+)
+
+func TestRenderFormats(t *testing.T) {
+	body := `<p><b>Bold</b> text</p><ul><li>one</li></ul>`
+	tests := []struct {
+		name      string
+		cfgFormat string
+		format    string
+		want      string
+	}{
+		{"default", "", "", body},
+		{"html config", FORMAT_HTML, "", body},
+		{"markdown config", FORMAT_MARKDOWN, "", "**Bold** text\n- one\n"},
+		{"markdown post", FORMAT_HTML, FORMAT_MARKDOWN, "**Bold** text\n- one\n"},
+		{"html post", FORMAT_MARKDOWN, FORMAT_HTML, body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prcr := newTestProcessor(t, Config{Format: tt.cfgFormat})
+			p := testPost("Rendered Post", body)
+			p.Format = tt.format
+			complete, apiErr := prcr.CreatePost(p)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			name, data, apiErr := prcr.Render(complete.Post.ID, apierror.MethodHTTP)
+			if apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			if !strings.HasSuffix(name, "-rendered-post.md") {
+				t.Errorf("rendered as %s", name)
+			}
+			parts := strings.SplitN(string(data), "---\n", 3)
+			if len(parts) != 3 || parts[0] != "" {
+				t.Fatalf("rendered post has no front matter:\n%s", data)
+			}
+			if parts[2] != tt.want {
+				t.Errorf("rendered body is\n%q\nwant\n%q", parts[2], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// Restore copies a previous revision into a new one so that it becomes the
+// latest version of its post. A posted post is regenerated from the restored
+// revision.
+func (prcr Processor) Restore(post_history_id int64, method string) (*database.CompletePost, apierror.IApiError) {
+	post_history, err := prcr.db.GetPostHistoryById(post_history_id)
+	if err != nil {
+		msg := "error getting post history in Restore: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if post_history == nil {
+		msg := "no post history found in Restore"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	_, err = prcr.db.RestorePostHistory(post_history, method)
+	if err != nil {
+		msg := "cannot restore post history: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	db_post, apiErr := prcr.findPost(post_history.PostID, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if db_post.Posted == database.DB_TRUE().Value() {
+		apiErr = prcr.publishPost(db_post, method)
+		if apiErr != nil {
+			apiErr := apierror.New(fmt.Errorf("cannot publish restored post: %w", apiErr), apiErr.Status(), method)
+			return nil, apiErr
+		}
+	}
+	return prcr.completePost(db_post, method)
+}
@@ -0,0 +1,98 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+// revise adds a revision with body and tag to the post titled "Restored
+// Post".
+func revise(t *testing.T, prcr Processor, post_id int64, body string, tag string) *database.CompletePost {
+	t.Helper()
+	p := testPost("Restored Post", body)
+	p.Tags = []string{tag}
+	complete, apiErr := prcr.UpdatePost(post_id, p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	return complete
+}
+
+func TestRestore(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	first := createTestPost(t, prcr, "Restored Post", "<p>first</p>")
+	revise(t, prcr, first.Post.ID, "<p>second</p>", "other")
+	restored, apiErr := prcr.Restore(first.History[0].ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(restored.History) != 3 {
+		t.Fatalf("post has %d revisions after restoring, want 3", len(restored.History))
+	}
+	latest, err := prcr.db.GetLatestPostHistory(restored.Post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID == first.History[0].ID {
+		t.Fatal("restoring did not add a revision")
+	}
+	if latest.Body != "<p>first</p>" {
+		t.Errorf("latest revision is %q, want the restored body", latest.Body)
+	}
+	tags, err := prcr.db.GetPostHistoryTags(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "test" {
+		t.Errorf("latest revision has tags %+v, want the restored tags", tags)
+	}
+	categories, err := prcr.db.GetPostHistoryCategories(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0].Name != "testing" {
+		t.Errorf("latest revision has categories %+v, want the restored categories", categories)
+	}
+}
+
+func TestRestorePublishedPost(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "_posts")
+	prcr := newTestProcessor(t, Config{Directory: dir})
+	first := createTestPost(t, prcr, "Restored Post", "<p>first</p>")
+	if _, apiErr := prcr.Publish(first.Post.ID, apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	revise(t, prcr, first.Post.ID, "<p>second</p>", "test")
+	restored, apiErr := prcr.Restore(first.History[0].ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if restored.Post.PublishedName == "" {
+		t.Fatal("restored post has no published file")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, restored.Post.PublishedName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "<p>first</p>") {
+		t.Errorf("published file was not regenerated from the restored revision:\n%s", data)
+	}
+}
+
+func TestRestoreMissingRevision(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	_, apiErr := prcr.Restore(12345, apierror.MethodHTTP)
+	if apiErr == nil {
+		t.Fatal("restored a revision that does not exist")
+	}
+	if apiErr.Status() != apierror.StatusNotFound {
+		t.Errorf("status %s, want %s", apiErr.Status(), apierror.StatusNotFound)
+	}
+}
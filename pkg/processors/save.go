@@ -2,24 +2,53 @@ package processors
 
 import (
 	"errors"
+	"fmt"
 
 	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
 	"gitlab.com/joshraphael/motdoftheday/pkg/database"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
-func (prcr Processor) SaveForm(p post.Post) apierror.IApiError {
+// SaveForm stores p as a new revision of a draft and returns that revision.
+// When the draft has changed since the revision p was edited from, the newer
+// revision is returned along with a CONFLICT error.
+func (prcr Processor) SaveForm(p post.Post) (*database.PostHistory, apierror.IApiError) {
 	err := p.Validate()
 	if err != nil {
-		msg := "invalid save post: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+		apiErr := apierror.New(fmt.Errorf("invalid save post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
 	}
-	err = prcr.db.CreatePost(p, database.DB_FALSE())
+	post_history_id, err := prcr.db.CreatePost(p, database.DB_FALSE())
 	if err != nil {
-		msg := "cannot save post: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+		return saveError("cannot save post: ", err, p.Method())
 	}
-	return nil
+	return prcr.revision(*post_history_id, p.Method())
+}
+
+// saveError reports an error storing a revision, which is a CONFLICT along
+// with the newer revision when the post changed since it was edited.
+func saveError(prefix string, err error, method string) (*database.PostHistory, apierror.IApiError) {
+	var conflict *database.ConflictError
+	if errors.As(err, &conflict) {
+		apiErr := apierror.New(fmt.Errorf("%s%w", prefix, conflict), apierror.StatusConflict, method)
+		return conflict.Latest, apiErr
+	}
+	msg := prefix + err.Error()
+	apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+	return nil, apiErr
+}
+
+func (prcr Processor) revision(post_history_id int64, method string) (*database.PostHistory, apierror.IApiError) {
+	post_history, err := prcr.db.GetPostHistoryById(post_history_id)
+	if err != nil {
+		msg := "error getting saved revision: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if post_history == nil {
+		msg := "no revision found after saving post"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return post_history, nil
 }
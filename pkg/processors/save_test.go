@@ -0,0 +1,137 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"errors"
+	"testing"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func TestSaveFormConflicts(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	first := createTestPost(t, prcr, "Saved Post", "<p>first</p>")
+	base := first.History[0].ID
+	second, apiErr := prcr.SaveForm(testPost("Saved Post", "<p>second</p>"))
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	missing := int64(12345)
+	zero := int64(0)
+	tests := []struct {
+		name   string
+		title  string
+		base   *int64
+		latest *database.PostHistory
+	}{
+		{"stale revision", "Saved Post", &base, second},
+		{"new post that exists", "Saved Post", &zero, second},
+		{"edited post that no longer exists", "Missing Post", &missing, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPost(tt.title, "<p>conflict</p>")
+			p.PostHistoryID = tt.base
+			revision, apiErr := prcr.SaveForm(p)
+			if apiErr == nil {
+				t.Fatal("saved a conflicting revision")
+			}
+			if apiErr.Status() != apierror.StatusConflict {
+				t.Fatalf("status %s, want %s: %v", apiErr.Status(), apierror.StatusConflict, apiErr)
+			}
+			var conflict *database.ConflictError
+			if !errors.As(apiErr, &conflict) {
+				t.Errorf("%v is not a ConflictError", apiErr)
+			}
+			if tt.latest == nil {
+				if revision != nil {
+					t.Errorf("got revision %+v of a post that does not exist", revision)
+				}
+				return
+			}
+			if revision == nil || revision.ID != tt.latest.ID || revision.Body != tt.latest.Body {
+				t.Errorf("got revision %+v, want the latest revision %+v", revision, tt.latest)
+			}
+		})
+	}
+	history, apiErr := prcr.History(first.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(history) != 2 {
+		t.Errorf("post has %d revisions after conflicting saves, want 2", len(history))
+	}
+}
+
+func TestSaveFormWithoutConflict(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	zero := int64(0)
+	p := testPost("Saved Post", "<p>first</p>")
+	p.PostHistoryID = &zero
+	first, apiErr := prcr.SaveForm(p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	p = testPost("Saved Post", "<p>second</p>")
+	p.PostHistoryID = &first.ID
+	second, apiErr := prcr.SaveForm(p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if second.Body != "<p>second</p>" {
+		t.Errorf("saved revision is %q", second.Body)
+	}
+	// Clients that do not send the revision they edited are not checked
+	third, apiErr := prcr.SaveForm(testPost("Saved Post", "<p>third</p>"))
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if third.ID == second.ID {
+		t.Error("saving without a base revision did not add a revision")
+	}
+}
+
+func TestUpdateConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		publish bool
+	}{
+		{"draft", false},
+		{"published", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prcr := newTestProcessor(t, Config{})
+			first := createTestPost(t, prcr, "Updated Post", "<p>first</p>")
+			if tt.publish {
+				if _, apiErr := prcr.Publish(first.Post.ID, apierror.MethodHTTP); apiErr != nil {
+					t.Fatal(apiErr)
+				}
+			}
+			base := first.History[0].ID
+			p := testPost("Updated Post", "<p>second</p>")
+			p.PostHistoryID = &base
+			if _, apiErr := prcr.UpdatePost(first.Post.ID, p); apiErr != nil {
+				t.Fatal(apiErr)
+			}
+			p = testPost("Updated Post", "<p>stale</p>")
+			p.PostHistoryID = &base
+			complete, apiErr := prcr.UpdatePost(first.Post.ID, p)
+			if apiErr == nil {
+				t.Fatal("updated a post from a stale revision")
+			}
+			if apiErr.Status() != apierror.StatusConflict {
+				t.Fatalf("status %s, want %s: %v", apiErr.Status(), apierror.StatusConflict, apiErr)
+			}
+			if complete == nil || len(complete.History) != 2 {
+				t.Fatalf("got post %+v along with the conflict, want the post with 2 revisions", complete)
+			}
+			latest := complete.History[len(complete.History)-1]
+			if latest.Body != "<p>second</p>" {
+				t.Errorf("latest revision is %q after a conflict", latest.Body)
+			}
+		})
+	}
+}
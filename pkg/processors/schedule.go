@@ -0,0 +1,70 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) SchedulePost(post_id int64, publish_at int64, method string) (*database.CompletePost, apierror.IApiError) {
+	if publish_at <= 0 {
+		msg := "publish_at must be a unix timestamp"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	return prcr.schedulePost(post_id, &publish_at, method)
+}
+
+func (prcr Processor) UnschedulePost(post_id int64, method string) (*database.CompletePost, apierror.IApiError) {
+	return prcr.schedulePost(post_id, nil, method)
+}
+
+// PublishDuePosts publishes every draft whose publish_at has passed. A post
+// that fails to publish does not stop the others and is retried on the next
+// call.
+func (prcr Processor) PublishDuePosts(now time.Time, method string) ([]database.Post, apierror.IApiError) {
+	db_posts, err := prcr.db.GetDuePosts(now.Unix())
+	if err != nil {
+		msg := "cannot get due posts: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	published := []database.Post{}
+	failures := []string{}
+	for i := range db_posts {
+		apiErr := prcr.publishPost(&db_posts[i], method)
+		if apiErr != nil {
+			failures = append(failures, "cannot publish post "+db_posts[i].UrlTitle+": "+apiErr.Error())
+			continue
+		}
+		published = append(published, db_posts[i])
+	}
+	if len(failures) > 0 {
+		msg := "cannot publish due posts: " + strings.Join(failures, "; ")
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return published, apiErr
+	}
+	return published, nil
+}
+
+func (prcr Processor) schedulePost(post_id int64, publish_at *int64, method string) (*database.CompletePost, apierror.IApiError) {
+	db_post, apiErr := prcr.findPost(post_id, method)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if db_post.Posted == database.DB_TRUE().Value() {
+		msg := "Post has already been posted for SchedulePost"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	err := prcr.db.SchedulePost(db_post, publish_at)
+	if err != nil {
+		msg := "cannot schedule post: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return prcr.GetPost(post_id, method)
+}
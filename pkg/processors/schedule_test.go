@@ -0,0 +1,116 @@
+//go:build sqlite_fts5
+
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func schedule(t *testing.T, prcr Processor, post_id int64, publish_at time.Time) {
+	t.Helper()
+	if _, apiErr := prcr.SchedulePost(post_id, publish_at.Unix(), apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+}
+
+func posted(t *testing.T, prcr Processor, post_id int64) bool {
+	t.Helper()
+	complete, apiErr := prcr.GetPost(post_id, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	return complete.Post.Posted == database.DB_TRUE().Value()
+}
+
+func TestPublishDuePosts(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	now := time.Now()
+	past := createTestPost(t, prcr, "Past Post", "<p>past</p>")
+	schedule(t, prcr, past.Post.ID, now.Add(-time.Hour))
+	due := createTestPost(t, prcr, "Due Post", "<p>due</p>")
+	schedule(t, prcr, due.Post.ID, now)
+	future := createTestPost(t, prcr, "Future Post", "<p>future</p>")
+	schedule(t, prcr, future.Post.ID, now.Add(time.Hour))
+	draft := createTestPost(t, prcr, "Draft Post", "<p>draft</p>")
+	already := createTestPost(t, prcr, "Already Posted", "<p>posted</p>")
+	schedule(t, prcr, already.Post.ID, now.Add(-time.Hour))
+	if _, apiErr := prcr.Publish(already.Post.ID, apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	published, apiErr := prcr.PublishDuePosts(now, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(published) != 2 || published[0].ID != past.Post.ID || published[1].ID != due.Post.ID {
+		t.Errorf("published %+v, want the past and due posts in publish_at order", published)
+	}
+	for _, complete := range []*database.CompletePost{past, due, already} {
+		if !posted(t, prcr, complete.Post.ID) {
+			t.Errorf("post %s is not posted", complete.Post.UrlTitle)
+		}
+	}
+	for _, complete := range []*database.CompletePost{future, draft} {
+		if posted(t, prcr, complete.Post.ID) {
+			t.Errorf("post %s was published early", complete.Post.UrlTitle)
+		}
+	}
+
+	published, apiErr = prcr.PublishDuePosts(now, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(published) != 0 {
+		t.Errorf("published %+v again", published)
+	}
+	published, apiErr = prcr.PublishDuePosts(now.Add(2*time.Hour), apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if len(published) != 1 || published[0].ID != future.Post.ID {
+		t.Errorf("published %+v, want the future post once it is due", published)
+	}
+}
+
+func TestPublishDuePostsFailure(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	now := time.Now()
+	broken := createTestPost(t, prcr, "Broken Post", `<p><img src="/api/assets/12345/missing.png"></p>`)
+	schedule(t, prcr, broken.Post.ID, now.Add(-2*time.Hour))
+	ok := createTestPost(t, prcr, "Working Post", "<p>works</p>")
+	schedule(t, prcr, ok.Post.ID, now.Add(-time.Hour))
+
+	published, apiErr := prcr.PublishDuePosts(now, apierror.MethodHTTP)
+	if apiErr == nil || apiErr.Status() != apierror.StatusInternal {
+		t.Errorf("got %v, want %s", apiErr, apierror.StatusInternal)
+	}
+	if len(published) != 1 || published[0].ID != ok.Post.ID {
+		t.Errorf("published %+v, want only the working post", published)
+	}
+	if posted(t, prcr, broken.Post.ID) {
+		t.Error("the broken post was marked as posted")
+	}
+	// The broken post is still due, so it is retried on the next call
+	_, apiErr = prcr.PublishDuePosts(now, apierror.MethodHTTP)
+	if apiErr == nil {
+		t.Error("the broken post was not retried")
+	}
+}
+
+func TestSchedulePostErrors(t *testing.T) {
+	prcr := newTestProcessor(t, Config{})
+	complete := createTestPost(t, prcr, "Scheduled Post", "<p>body</p>")
+	if _, apiErr := prcr.SchedulePost(complete.Post.ID, 0, apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v scheduling at 0, want %s", apiErr, apierror.StatusBadRequest)
+	}
+	if _, apiErr := prcr.Publish(complete.Post.ID, apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if _, apiErr := prcr.SchedulePost(complete.Post.ID, time.Now().Unix(), apierror.MethodHTTP); apiErr == nil || apiErr.Status() != apierror.StatusBadRequest {
+		t.Errorf("got %v scheduling a posted post, want %s", apiErr, apierror.StatusBadRequest)
+	}
+}
@@ -0,0 +1,24 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) Search(query string, filters database.SearchFilters, method string) ([]database.SearchResult, apierror.IApiError) {
+	if strings.TrimSpace(query) == "" {
+		msg := "search query is required"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return nil, apiErr
+	}
+	results, err := prcr.db.SearchPosts(query, filters)
+	if err != nil {
+		msg := "cannot search posts: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return results, nil
+}
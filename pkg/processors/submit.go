@@ -2,30 +2,40 @@ package processors
 
 import (
 	"errors"
+	"fmt"
 
 	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
 	"gitlab.com/joshraphael/motdoftheday/pkg/database"
 	"gitlab.com/joshraphael/motdoftheday/pkg/post"
 )
 
-func (prcr Processor) SubmitForm(p post.Post) apierror.IApiError {
+// SubmitForm stores p as a new revision, publishes it and returns that
+// revision. When the post has changed since the revision p was edited from,
+// the newer revision is returned along with a CONFLICT error.
+func (prcr Processor) SubmitForm(p post.Post) (*database.PostHistory, apierror.IApiError) {
 	err := p.Validate()
 	if err != nil {
-		msg := "invalid submit post: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+		apiErr := apierror.New(fmt.Errorf("invalid submit post: %w", err), apierror.StatusBadRequest, p.Method())
+		return nil, apiErr
 	}
-	err = prcr.db.CreatePost(p, database.DB_TRUE())
+	db_post, err := prcr.db.GetPostByUrlTitle(p.UrlTitle())
 	if err != nil {
-		msg := "cannot submit post: " + err.Error()
-		apiErr := apierror.New(errors.New(msg), "BAD_REQUEST", p.Method())
-		return apiErr
+		msg := "error getting post in SubmitForm: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, p.Method())
+		return nil, apiErr
+	}
+	if db_post != nil && db_post.Posted == database.DB_TRUE().Value() {
+		// Submitting a post that is live updates it in place
+		return prcr.revisePublishedPost(db_post, p)
+	}
+	post_history_id, err := prcr.db.CreatePost(p, database.DB_TRUE())
+	if err != nil {
+		return saveError("cannot submit post: ", err, p.Method())
 	}
 	ae := prcr.generatePost(p)
 	if ae != nil {
-		msg := "cannot generate post: " + ae.Error()
-		apiErr := apierror.New(errors.New(msg), ae.Status(), p.Method())
-		return apiErr
+		apiErr := apierror.New(fmt.Errorf("cannot generate post: %w", ae), ae.Status(), p.Method())
+		return nil, apiErr
 	}
-	return nil
+	return prcr.revision(*post_history_id, p.Method())
 }
@@ -0,0 +1,18 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+)
+
+func (prcr Processor) Tags(method string) ([]database.Tag, apierror.IApiError) {
+	tags, err := prcr.db.GetTags()
+	if err != nil {
+		msg := "cannot get tags: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return tags, nil
+}
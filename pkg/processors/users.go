@@ -0,0 +1,99 @@
+package processors
+
+import (
+	"errors"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/user"
+)
+
+func (prcr Processor) Users(method string) ([]database.User, apierror.IApiError) {
+	users, err := prcr.db.GetUsers()
+	if err != nil {
+		msg := "cannot get users: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	return users, nil
+}
+
+func (prcr Processor) GetUser(user_id int64, method string) (*database.User, apierror.IApiError) {
+	db_user, err := prcr.db.GetUserById(user_id)
+	if err != nil {
+		msg := "error getting user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if db_user == nil {
+		msg := "no user exists with that id"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return db_user, nil
+}
+
+func (prcr Processor) GetUserByUsername(username string, method string) (*database.User, apierror.IApiError) {
+	db_user, err := prcr.db.GetUserByUsername(username)
+	if err != nil {
+		msg := "error getting user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusInternal, method)
+		return nil, apiErr
+	}
+	if db_user == nil {
+		msg := "no user exists with name '" + username + "'"
+		apiErr := apierror.New(errors.New(msg), apierror.StatusNotFound, method)
+		return nil, apiErr
+	}
+	return db_user, nil
+}
+
+func (prcr Processor) CreateUser(u user.User) (*database.User, apierror.IApiError) {
+	err := u.Validate()
+	if err != nil {
+		msg := "invalid user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, u.Method())
+		return nil, apiErr
+	}
+	db_user, err := prcr.db.CreateUser(u)
+	if err != nil {
+		msg := "cannot create user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, u.Method())
+		return nil, apiErr
+	}
+	return db_user, nil
+}
+
+func (prcr Processor) UpdateUser(user_id int64, u user.User) (*database.User, apierror.IApiError) {
+	err := u.Validate()
+	if err != nil {
+		msg := "invalid user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, u.Method())
+		return nil, apiErr
+	}
+	db_user, apiErr := prcr.GetUser(user_id, u.Method())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	err = prcr.db.UpdateUser(db_user, u)
+	if err != nil {
+		msg := "cannot update user: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, u.Method())
+		return nil, apiErr
+	}
+	return prcr.GetUser(user_id, u.Method())
+}
+
+func (prcr Processor) DeleteUser(user_id int64, method string) apierror.IApiError {
+	db_user, apiErr := prcr.GetUser(user_id, method)
+	if apiErr != nil {
+		return apiErr
+	}
+	err := prcr.db.DeleteUser(db_user)
+	if err != nil {
+		msg := "cannot delete user, they may still own posts: " + err.Error()
+		apiErr := apierror.New(errors.New(msg), apierror.StatusBadRequest, method)
+		return apiErr
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package publisher
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// File writes posts to a directory on the local filesystem.
+type File struct {
+	dir string
+}
+
+func NewFile(dir string) File {
+	return File{
+		dir: dir,
+	}
+}
+
+// Write writes data to name under the directory, creating the directories it
+// is in.
+func (f File) Write(name string, data []byte, message string) error {
+	filename := filepath.Join(f.dir, filepath.FromSlash(name))
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		msg := "cannot create post dir " + dir + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		msg := "cannot write post file " + filename + ": " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// Delete removes name from the directory.
+func (f File) Delete(name string, message string) error {
+	filename := filepath.Join(f.dir, filepath.FromSlash(name))
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		msg := "cannot remove post file " + filename + ": " + err.Error()
+		return errors.New(msg)
+	}
+	// Posts kept in their own directory leave it empty, which is removed too
+	for dir := filepath.Dir(filename); dir != filepath.Clean(f.dir); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List returns every file under the directory, which does not need to exist
+// yet.
+func (f File) List() ([]string, error) {
+	names := []string{}
+	if _, err := os.Stat(f.dir); os.IsNotExist(err) {
+		return names, nil
+	}
+	err := filepath.Walk(f.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		msg := "cannot list post dir " + f.dir + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,179 @@
+package publisher
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitConfig turns the processors output directory into a git working tree,
+// typically a checkout of the Jekyll site. Leaving Remote empty commits
+// without pushing.
+type GitConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	PostDir string `yaml:"post_dir"`
+	Remote  string `yaml:"remote"`
+	Branch  string `yaml:"branch"`
+	Name    string `yaml:"name"`
+	Email   string `yaml:"email"`
+}
+
+const defaultPostDir string = "_posts"
+
+// Git commits posts to the post directory of a git working tree and pushes
+// each commit to the configured remote.
+type Git struct {
+	mu  *sync.Mutex
+	dir string
+	cfg GitConfig
+}
+
+func NewGit(dir string, cfg GitConfig) Git {
+	if cfg.PostDir == "" {
+		cfg.PostDir = defaultPostDir
+	}
+	return Git{
+		mu:  &sync.Mutex{},
+		dir: dir,
+		cfg: cfg,
+	}
+}
+
+// Dir returns a publisher for another directory of the same working tree,
+// sharing the lock of g so that their commits never overlap.
+func (g Git) Dir(dir string) Git {
+	cfg := g.cfg
+	cfg.PostDir = dir
+	return Git{
+		mu:  g.mu,
+		dir: g.dir,
+		cfg: cfg,
+	}
+}
+
+// Write writes data to name under the post directory, commits it with
+// message when it changed and pushes to the configured remote.
+func (g Git) Write(name string, data []byte, message string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := g.git("rev-parse", "--is-inside-work-tree"); err != nil {
+		msg := "directory " + g.dir + " is not a git working tree: " + err.Error()
+		return errors.New(msg)
+	}
+	rel := filepath.Join(g.cfg.PostDir, filepath.FromSlash(name))
+	post_dir := filepath.Dir(filepath.Join(g.dir, rel))
+	if err := os.MkdirAll(post_dir, os.ModePerm); err != nil {
+		msg := "cannot create post dir " + post_dir + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if err := ioutil.WriteFile(filepath.Join(g.dir, rel), data, 0644); err != nil {
+		msg := "cannot write post file " + rel + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if _, err := g.git("add", "--", rel); err != nil {
+		msg := "cannot stage " + rel + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if _, err := g.git("diff", "--cached", "--quiet", "--", rel); err != nil {
+		if _, err := g.git("commit", "-m", message, "--", rel); err != nil {
+			msg := "cannot commit " + rel + ": " + err.Error()
+			return errors.New(msg)
+		}
+	}
+	// Push even when nothing changed, so that writing a post again retries
+	// a commit whose push failed
+	return g.push()
+}
+
+// Delete removes name from the post directory, commits the removal with
+// message and pushes to the configured remote. Deleting a file that is not
+// tracked only removes it from the working tree before pushing.
+func (g Git) Delete(name string, message string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := g.git("rev-parse", "--is-inside-work-tree"); err != nil {
+		msg := "directory " + g.dir + " is not a git working tree: " + err.Error()
+		return errors.New(msg)
+	}
+	rel := filepath.Join(g.cfg.PostDir, filepath.FromSlash(name))
+	if _, err := g.git("ls-files", "--error-unmatch", "--", rel); err != nil {
+		if err := os.Remove(filepath.Join(g.dir, rel)); err != nil && !os.IsNotExist(err) {
+			msg := "cannot remove post file " + rel + ": " + err.Error()
+			return errors.New(msg)
+		}
+		// The removal may have been committed by a delete whose push failed
+		return g.push()
+	}
+	if _, err := g.git("rm", "-q", "--", rel); err != nil {
+		msg := "cannot remove " + rel + ": " + err.Error()
+		return errors.New(msg)
+	}
+	if _, err := g.git("commit", "-m", message, "--", rel); err != nil {
+		msg := "cannot commit removal of " + rel + ": " + err.Error()
+		return errors.New(msg)
+	}
+	return g.push()
+}
+
+// List returns the files tracked in the post directory.
+func (g Git) List() ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out, err := g.git("ls-files", "-z", "--", g.cfg.PostDir)
+	if err != nil {
+		msg := "cannot list " + g.cfg.PostDir + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	prefix := filepath.ToSlash(filepath.Clean(g.cfg.PostDir)) + "/"
+	names := []string{}
+	for _, file := range strings.Split(out, "\x00") {
+		if file == "" {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(file, prefix))
+	}
+	return names, nil
+}
+
+func (g Git) push() error {
+	if g.cfg.Remote == "" {
+		return nil
+	}
+	ref := "HEAD"
+	if g.cfg.Branch != "" {
+		ref = "HEAD:" + g.cfg.Branch
+	}
+	if _, err := g.git("push", g.cfg.Remote, ref); err != nil {
+		msg := "cannot push to " + g.cfg.Remote + ": " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (g Git) git(args ...string) (string, error) {
+	full := []string{"-C", g.dir}
+	if g.cfg.Name != "" {
+		full = append(full, "-c", "user.name="+g.cfg.Name)
+	}
+	if g.cfg.Email != "" {
+		full = append(full, "-c", "user.email="+g.cfg.Email)
+	}
+	full = append(full, args...)
+	cmd := exec.Command("git", full...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := "git " + strings.Join(args, " ") + ": " + err.Error()
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			msg += ": " + s
+		}
+		return "", errors.New(msg)
+	}
+	return stdout.String(), nil
+}
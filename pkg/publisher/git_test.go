@@ -0,0 +1,127 @@
+package publisher
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestGit returns a publisher for a new working tree that pushes to a new
+// bare repository, along with the path of the bare repository.
+func newTestGit(t *testing.T) (Git, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	testGit(t, "", "init", "-q", "--bare", remote)
+	testGit(t, "", "init", "-q", work)
+	g := NewGit(work, GitConfig{
+		Enabled: true,
+		Remote:  remote,
+		Branch:  "main",
+		Name:    "Test",
+		Email:   "test@example.com",
+	})
+	return g, remote
+}
+
+// testGit runs git in dir, or in the working directory when dir is empty.
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// remoteFile returns name under _posts on the main branch of remote, and
+// false when it is not there.
+func remoteFile(t *testing.T, remote string, name string) (string, bool) {
+	t.Helper()
+	out, err := exec.Command("git", "-C", remote, "show", "main:_posts/"+name).Output()
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func TestGitWriteCommitsAndPushes(t *testing.T) {
+	g, remote := newTestGit(t)
+	if err := g.Write("2020-01-01-post.md", []byte("first"), "Add post"); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := remoteFile(t, remote, "2020-01-01-post.md"); !ok || data != "first" {
+		t.Fatalf("remote has %q, want %q", data, "first")
+	}
+	if err := g.Write("2020-01-01-post.md", []byte("second"), "Update post"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := remoteFile(t, remote, "2020-01-01-post.md"); data != "second" {
+		t.Errorf("remote has %q, want %q", data, "second")
+	}
+	if err := g.Write("2020-01-01-post.md", []byte("second"), "Update post"); err != nil {
+		t.Fatal(err)
+	}
+	log := testGit(t, remote, "log", "--format=%s", "main")
+	if log != "Update post\nAdd post\n" {
+		t.Errorf("remote log is %q, writing an unchanged post should not commit", log)
+	}
+	names, err := g.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "2020-01-01-post.md" {
+		t.Errorf("List() = %v", names)
+	}
+	if err := g.Delete("2020-01-01-post.md", "Remove post"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := remoteFile(t, remote, "2020-01-01-post.md"); ok {
+		t.Error("remote still has the deleted post")
+	}
+}
+
+func TestGitRetriesFailedPush(t *testing.T) {
+	g, remote := newTestGit(t)
+	offline := remote + ".offline"
+	if err := os.Rename(remote, offline); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Write("2020-01-01-post.md", []byte("post"), "Add post"); err == nil {
+		t.Fatal("Write succeeded without a remote")
+	}
+	if err := os.Rename(offline, remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Write("2020-01-01-post.md", []byte("post"), "Add post"); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := remoteFile(t, remote, "2020-01-01-post.md"); !ok || data != "post" {
+		t.Fatalf("retrying the write did not push the commit, remote has %q", data)
+	}
+
+	if err := os.Rename(remote, offline); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Delete("2020-01-01-post.md", "Remove post"); err == nil {
+		t.Fatal("Delete succeeded without a remote")
+	}
+	if err := os.Rename(offline, remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Delete("2020-01-01-post.md", "Remove post"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := remoteFile(t, remote, "2020-01-01-post.md"); ok {
+		t.Error("retrying the delete did not push the removal")
+	}
+}
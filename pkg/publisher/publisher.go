@@ -0,0 +1,13 @@
+package publisher
+
+// Publisher stores the generated files of posts on the site. Names are
+// relative to the post directory of the site, and message describes the
+// change for publishers that keep a history of the site.
+type Publisher interface {
+	// Write creates or replaces the file name with data
+	Write(name string, data []byte, message string) error
+	// Delete removes the file name, doing nothing when it does not exist
+	Delete(name string, message string) error
+	// List returns the names of every file in the post directory
+	List() ([]string, error)
+}
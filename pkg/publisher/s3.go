@@ -0,0 +1,291 @@
+package publisher
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config stores posts as objects in a bucket of an S3 compatible object
+// store such as MinIO. Stores that do not serve buckets as sub domains of the
+// endpoint, which includes a local MinIO, need PathStyle.
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	Prefix    string `yaml:"prefix"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	PathStyle bool   `yaml:"path_style"`
+}
+
+const (
+	defaultRegion   string = "us-east-1"
+	s3Service       string = "s3"
+	s3Algorithm     string = "AWS4-HMAC-SHA256"
+	s3DateFormat    string = "20060102T150405Z"
+	s3ScopeFormat   string = "20060102"
+	s3ContentHash   string = "x-amz-content-sha256"
+	s3DateHeader    string = "x-amz-date"
+	s3ClientTimeout        = 30 * time.Second
+)
+
+// S3 writes posts to a bucket with the S3 REST API, signing each request with
+// AWS signature version 4.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+func NewS3(cfg S3Config) S3 {
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPostDir
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/") + "/"
+	return S3{
+		cfg:    cfg,
+		client: &http.Client{Timeout: s3ClientTimeout},
+	}
+}
+
+// Write puts data in the object for name.
+func (s S3) Write(name string, data []byte, message string) error {
+	key := s.cfg.Prefix + name
+	header := http.Header{}
+	content_type := mime.TypeByExtension(path.Ext(name))
+	if content_type == "" {
+		content_type = "text/plain; charset=utf-8"
+	}
+	header.Set("Content-Type", content_type)
+	_, err := s.do("PUT", key, nil, header, data)
+	if err != nil {
+		msg := "cannot put object " + key + ": " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// Delete removes the object for name. S3 reports success for objects that do
+// not exist.
+func (s S3) Delete(name string, message string) error {
+	key := s.cfg.Prefix + name
+	_, err := s.do("DELETE", key, nil, http.Header{}, nil)
+	if err != nil {
+		msg := "cannot delete object " + key + ": " + err.Error()
+		return errors.New(msg)
+	}
+	return nil
+}
+
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+// List returns the name of every object under the prefix.
+func (s S3) List() ([]string, error) {
+	names := []string{}
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.cfg.Prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		body, err := s.do("GET", "", query, http.Header{}, nil)
+		if err != nil {
+			msg := "cannot list objects in " + s.cfg.Bucket + ": " + err.Error()
+			return nil, errors.New(msg)
+		}
+		var result s3ListResult
+		err = xml.Unmarshal(body, &result)
+		if err != nil {
+			msg := "cannot read object list of " + s.cfg.Bucket + ": " + err.Error()
+			return nil, errors.New(msg)
+		}
+		for i := range result.Contents {
+			names = append(names, strings.TrimPrefix(result.Contents[i].Key, s.cfg.Prefix))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request for key, or for the bucket when key is empty, and
+// returns the response body.
+func (s S3) do(method string, key string, query url.Values, header http.Header, data []byte) ([]byte, error) {
+	if s.cfg.Bucket == "" {
+		return nil, errors.New("no bucket configured")
+	}
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	req.ContentLength = int64(len(data))
+	s.sign(req, data, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := resp.Status
+		var e s3Error
+		if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+			msg += ": " + e.Code + ": " + e.Message
+		}
+		return nil, errors.New(msg)
+	}
+	return body, nil
+}
+
+// objectURL returns the url of key in the bucket, which is a sub domain of
+// the endpoint unless the store uses path style urls.
+func (s S3) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		msg := "invalid endpoint " + s.cfg.Endpoint + ": " + err.Error()
+		return nil, errors.New(msg)
+	}
+	object_path := strings.TrimSuffix(u.Path, "/") + "/"
+	if s.cfg.PathStyle {
+		object_path += s.cfg.Bucket + "/"
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	object_path += key
+	u.Path = object_path
+	u.RawPath = s3Escape(object_path, false)
+	return u, nil
+}
+
+// sign adds the AWS signature version 4 Authorization header to req, signing
+// the host and every header already set on it.
+func (s S3) sign(req *http.Request, data []byte, now time.Time) {
+	now = now.UTC()
+	amz_date := now.Format(s3DateFormat)
+	payload_sum := sha256.Sum256(data)
+	payload_hash := hex.EncodeToString(payload_sum[:])
+	req.Header.Set(s3DateHeader, amz_date)
+	req.Header.Set(s3ContentHash, payload_hash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	header_names := []string{}
+	for k := range headers {
+		header_names = append(header_names, k)
+	}
+	sort.Strings(header_names)
+	canonical_headers := ""
+	for _, k := range header_names {
+		canonical_headers += k + ":" + headers[k] + "\n"
+	}
+	signed_headers := strings.Join(header_names, ";")
+
+	canonical_request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonical_headers,
+		signed_headers,
+		payload_hash,
+	}, "\n")
+	request_sum := sha256.Sum256([]byte(canonical_request))
+
+	scope := now.Format(s3ScopeFormat) + "/" + s.cfg.Region + "/" + s3Service + "/aws4_request"
+	string_to_sign := s3Algorithm + "\n" + amz_date + "\n" + scope + "\n" + hex.EncodeToString(request_sum[:])
+
+	key := s3HMAC([]byte("AWS4"+s.cfg.SecretKey), now.Format(s3ScopeFormat))
+	key = s3HMAC(key, s.cfg.Region)
+	key = s3HMAC(key, s3Service)
+	key = s3HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(s3HMAC(key, string_to_sign))
+
+	req.Header.Set("Authorization", s3Algorithm+" Credential="+s.cfg.AccessKey+"/"+scope+", SignedHeaders="+signed_headers+", Signature="+signature)
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3CanonicalQuery sorts the query by name and value and escapes it the way
+// signature version 4 expects.
+func s3CanonicalQuery(query url.Values) string {
+	pairs := []string{}
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent encodes every byte of s except the unreserved characters
+// and, unless escape_slash is set, the path separator.
+func s3Escape(s string, escape_slash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == '/' && !escape_slash:
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,5 @@
+package scheduler
+
+type Config struct {
+	Interval string `yaml:"interval"`
+}
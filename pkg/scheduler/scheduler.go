@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+const defaultInterval time.Duration = time.Minute
+
+// Scheduler periodically publishes drafts whose publish_at time has passed.
+type Scheduler struct {
+	processor processors.Processor
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func New(cfg Config, p processors.Processor) (*Scheduler, error) {
+	interval := defaultInterval
+	if cfg.Interval != "" {
+		d, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			msg := "invalid scheduler interval '" + cfg.Interval + "': " + err.Error()
+			return nil, errors.New(msg)
+		}
+		if d <= 0 {
+			msg := "scheduler interval '" + cfg.Interval + "' must be positive"
+			return nil, errors.New(msg)
+		}
+		interval = d
+	}
+	return &Scheduler{
+		processor: p,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Start runs the scheduler in a background goroutine until Shutdown is called.
+func (s *Scheduler) Start() {
+	go s.run()
+}
+
+// Shutdown stops the scheduler and waits for a publish that is in progress to
+// finish, or for ctx to expire.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	close(s.stop)
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		msg := "scheduler did not stop: " + ctx.Err().Error()
+		return errors.New(msg)
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.publish()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) publish() {
+	published, apiErr := s.processor.PublishDuePosts(time.Now(), apierror.MethodHTTP)
+	for i := range published {
+		log.Println("Published scheduled post: " + published[i].UrlTitle)
+	}
+	if apiErr != nil {
+		log.Println("Error publishing scheduled posts: " + apiErr.Error())
+	}
+}
@@ -0,0 +1,107 @@
+//go:build sqlite_fts5
+
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gitlab.com/joshraphael/motdoftheday/pkg/apierror"
+	"gitlab.com/joshraphael/motdoftheday/pkg/database"
+	"gitlab.com/joshraphael/motdoftheday/pkg/post"
+	"gitlab.com/joshraphael/motdoftheday/pkg/processors"
+)
+
+func newTestProcessor(t *testing.T) processors.Processor {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Database files are opened relative to the working directory
+	file, err := filepath.Rel(wd, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, sqlxDB, err := database.New(database.Config{File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+	return processors.New(processors.Config{Directory: filepath.Join(t.TempDir(), "_posts")}, db)
+}
+
+func TestNew(t *testing.T) {
+	prcr := newTestProcessor(t)
+	s, err := New(Config{}, prcr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.interval != defaultInterval {
+		t.Errorf("got interval %s, want %s", s.interval, defaultInterval)
+	}
+	for _, interval := range []string{"soon", "0s", "-1m"} {
+		if _, err := New(Config{Interval: interval}, prcr); err == nil {
+			t.Errorf("interval %q was accepted", interval)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	prcr := newTestProcessor(t)
+	p := post.New(apierror.MethodHTTP, "admin")
+	p.Title = "Scheduled Post"
+	p.Tags = []string{"test"}
+	p.Categories = []string{"testing"}
+	p.Body = "<p>body</p>"
+	complete, apiErr := prcr.CreatePost(p)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if _, apiErr := prcr.SchedulePost(complete.Post.ID, time.Now().Add(-time.Minute).Unix(), apierror.MethodHTTP); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	s, err := New(Config{Interval: "1h"}, prcr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("Shutdown returned before the worker stopped")
+	}
+	// The worker publishes once when it starts, and Shutdown waits for it
+	complete, apiErr = prcr.GetPost(complete.Post.ID, apierror.MethodHTTP)
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if complete.Post.Posted != database.DB_TRUE().Value() {
+		t.Error("the due post was not published before the scheduler stopped")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	s, err := New(Config{}, newTestProcessor(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without Start there is no worker to stop
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err == nil {
+		t.Error("Shutdown returned without the worker stopping")
+	}
+}
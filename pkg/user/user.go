@@ -0,0 +1,40 @@
+package user
+
+import (
+	"errors"
+	"regexp"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type User struct {
+	validator *validator.Validate
+	method    string
+	Username  string `json:"user_name" validate:"required"`
+	Firstname string `json:"first_name" validate:"required"`
+	Lastname  string `json:"last_name" validate:"required"`
+}
+
+func New(m string) User {
+	return User{
+		validator: validator.New(),
+		method:    m,
+	}
+}
+
+func (u User) Method() string {
+	return u.method
+}
+
+func (u User) Validate() error {
+	if err := u.validator.Struct(u); err != nil {
+		msg := "error validating user: " + err.Error()
+		return errors.New(msg)
+	}
+	urlSafe := regexp.MustCompile(`^[a-zA-Z0-9-_]{1,40}$`)
+	if !urlSafe.MatchString(u.Username) {
+		msg := "user name '" + u.Username + "' not URL safe"
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -6,4 +6,5 @@ DELETE FROM post_tags;
 DELETE FROM category;
 DELETE FROM tag;
 DELETE FROM post;
-DELETE FROM user;
\ No newline at end of file
+DELETE FROM asset;
+DELETE FROM working_copy;
\ No newline at end of file
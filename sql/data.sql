@@ -1,7 +1,5 @@
 PRAGMA foreign_keys = ON;
 
-INSERT INTO user (user_name, first_name, last_name) VALUES(LOWER("Admin"), "ADMIN", "ADMIN");
-
 INSERT INTO post (url_title, user_id, title, posted) VALUES(LOWER("Sample-post"), (
     SELECT id
     FROM user
@@ -12,4 +12,85 @@ $(document).ready(function () {
         var post_history_id = $("#history").children(":selected").attr("id");
         window.location.href = '/edit/' + post_history_id;
     })
-})
\ No newline at end of file
+    $("#restore-button").on("click", function () {
+        var post_history_id = $("#history").children(":selected").attr("id");
+        $.post("/api/history/" + post_history_id + "/restore", function () {
+            window.location.reload();
+        }).fail(function (data) {
+            $("#restore-status").text(errorMessage(data));
+        });
+    })
+    $("#schedule-button").on("click", function () {
+        var post_id = $(this).data("post-id");
+        var publish_at = Math.floor(new Date($("#schedule-time").val()).getTime() / 1000);
+        $.ajax({
+            url: "/api/posts/" + post_id + "/schedule",
+            type: "PUT",
+            data: JSON.stringify({ publish_at: publish_at })
+        }).done(function () {
+            window.location.reload();
+        }).fail(function (data) {
+            $("#schedule-status").text(errorMessage(data));
+        });
+    })
+    $("#unschedule-button").on("click", function () {
+        var post_id = $(this).data("post-id");
+        $.ajax({
+            url: "/api/posts/" + post_id + "/schedule",
+            type: "DELETE"
+        }).done(function () {
+            window.location.reload();
+        }).fail(function (data) {
+            $("#schedule-status").text(errorMessage(data));
+        });
+    })
+    $("#publish-button").on("click", function () {
+        var post_id = $(this).data("post-id");
+        $.post("/api/posts/" + post_id + "/publish", function () {
+            window.location.reload();
+        }).fail(function (data) {
+            $("#publish-status").text(errorMessage(data));
+        });
+    })
+    $("#unpublish-button").on("click", function () {
+        var post_id = $(this).data("post-id");
+        $.post("/api/posts/" + post_id + "/unpublish", function () {
+            window.location.reload();
+        }).fail(function (data) {
+            $("#publish-status").text(errorMessage(data));
+        });
+    })
+    $("#diff-button").on("click", function () {
+        var post_id = $(this).data("post-id");
+        var from = $("#diff-from").val();
+        var to = $("#history").children(":selected").attr("id");
+        $.get("/api/posts/" + post_id + "/diff", { from: from, to: to }, function (diff) {
+            $("#diff-status").empty();
+            $("#diff-tags").empty().append(diffNames("fa fa-tag", diff.tags_added, diff.tags_removed));
+            $("#diff-categories").empty().append(diffNames("fa fa-list", diff.categories_added, diff.categories_removed));
+            var body = $("#diff-body").empty();
+            $.each(diff.body, function (i, op) {
+                if (op.op === "insert") {
+                    body.append($("<ins>").text(op.text));
+                } else if (op.op === "delete") {
+                    body.append($("<del>").text(op.text));
+                } else {
+                    body.append($("<span>").text(op.text));
+                }
+            });
+        }).fail(function (data) {
+            $("#diff-status").text(errorMessage(data));
+        });
+    })
+})
+
+function diffNames(icon, added, removed) {
+    var names = [];
+    $.each(added, function (i, name) {
+        names.push($("<ins>").addClass(icon).text(name));
+    });
+    $.each(removed, function (i, name) {
+        names.push($("<del>").addClass(icon).text(name));
+    });
+    return names;
+}
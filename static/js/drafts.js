@@ -0,0 +1,34 @@
+$(document).ready(function () {
+    function search() {
+        var query = $("#search-query").val();
+        if ($.trim(query) === "") {
+            $("#search-results").empty();
+            $("#drafts").show();
+            return;
+        }
+        $.getJSON("/api/search", { q: query }, function (results) {
+            var list = $("#search-results").empty();
+            $.each(results, function (i, result) {
+                var item = $("<li>");
+                $("<a>").attr("href", "/drafts/" + result.id).text(result.title).appendTo(item);
+                if (result.posted) {
+                    item.append(" (published)");
+                }
+                $("<div>").text(result.snippet).appendTo(item);
+                list.append(item);
+            });
+            if (results.length === 0) {
+                list.append($("<li>").text("No posts found"));
+            }
+            $("#drafts").hide();
+        }).fail(function (data) {
+            $("#search-results").empty().append($("<li>").text(errorMessage(data)));
+        });
+    }
+    $("#search-button").on("click", search);
+    $("#search-query").on("keyup", function (e) {
+        if (e.key === "Enter" || $.trim($(this).val()) === "") {
+            search();
+        }
+    });
+})
@@ -0,0 +1,24 @@
+// errorMessage returns the message of a failed request, which the server
+// sends as a JSON error envelope
+function errorMessage(data) {
+    if (data.responseJSON && data.responseJSON.message) {
+        return data.responseJSON.message;
+    }
+    return data.responseText;
+}
+
+// clearErrors unmarks the fields marked by showError
+function clearErrors() {
+    $(".motdoftheday-invalid").removeClass("motdoftheday-invalid").css("border-color", "").removeAttr("title");
+}
+
+// showError puts the message of a failed request in status and marks the
+// fields of the form that are not valid
+function showError(status, data) {
+    clearErrors();
+    status.text(errorMessage(data));
+    var fields = data.responseJSON && data.responseJSON.fields || [];
+    for (var i = 0; i < fields.length; i++) {
+        $("#motdoftheday-" + fields[i].field).addClass("motdoftheday-invalid").css("border-color", "red").attr("title", fields[i].message);
+    }
+}
@@ -0,0 +1,10 @@
+// Package motdoftheday holds the pages and static files of the web editor,
+// which are embedded so that the binary runs from any directory.
+package motdoftheday
+
+import "embed"
+
+// Web is the templates/ and static/ directories of the repository.
+//
+//go:embed templates/*.html static
+var Web embed.FS